@@ -0,0 +1,314 @@
+// Package bed reads and writes BED formatted genomic intervals,
+// from BED3 to BED12, with optional bedDetail and extra columns.
+//
+// BED coordinates are 0-based and half-open: the first base of a
+// chromosome is at position 0 and End is one past the last base,
+// so the length of a feature is End - Start.
+package bed
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pmagwene/biofiles/interval"
+)
+
+// Record is a representation of a single BED line. Fields is the
+// number of standard BED columns (3 to 12) read or to be written;
+// columns beyond these are kept in Extra. For bedDetail files the
+// final two columns are held in ID and Description instead.
+type Record struct {
+	Chrom       string
+	Start       int
+	End         int
+	Name        string
+	Score       int
+	Strand      string
+	ThickStart  int
+	ThickEnd    int
+	ItemRGB     string
+	BlockSizes  []int
+	BlockStarts []int // relative to Start
+
+	Fields      int
+	Extra       []string
+	Detail      bool
+	ID          string
+	Description string
+}
+
+// Block is an interval in 0-based half-open chromosome coordinates
+type Block struct {
+	Start int
+	End   int
+}
+
+// Len returns the length of the feature
+func (r *Record) Len() int {
+	return r.End - r.Start
+}
+
+// Interval returns the extent of the record, in 0-based half-open
+// coordinates
+func (r *Record) Interval() interval.Interval {
+	iv := interval.Interval{SeqID: r.Chrom, Start: r.Start, End: r.End,
+		Coords: interval.ZeroBased}
+	if r.Strand == "+" || r.Strand == "-" {
+		iv.Strand = r.Strand[0]
+	}
+	return iv
+}
+
+// Blocks returns the blocks (typically exons) of the record in
+// chromosome coordinates. A record without blocks is a single
+// block spanning the whole feature.
+func (r *Record) Blocks() []Block {
+	if len(r.BlockSizes) == 0 {
+		return []Block{{r.Start, r.End}}
+	}
+	blocks := make([]Block, len(r.BlockSizes))
+	for i, size := range r.BlockSizes {
+		start := r.Start + r.BlockStarts[i]
+		blocks[i] = Block{start, start + size}
+	}
+	return blocks
+}
+
+// SetBlocks sets the blocks of the record from blocks in
+// chromosome coordinates, which must be sorted and lie within the
+// feature
+func (r *Record) SetBlocks(blocks []Block) {
+	r.BlockSizes = make([]int, len(blocks))
+	r.BlockStarts = make([]int, len(blocks))
+	for i, b := range blocks {
+		r.BlockSizes[i] = b.End - b.Start
+		r.BlockStarts[i] = b.Start - r.Start
+	}
+}
+
+// ThickBlocks returns the parts of the blocks within the thick
+// region, typically the coding sequence. It is empty if the thick
+// region is empty.
+func (r *Record) ThickBlocks() []Block {
+	var blocks []Block
+	for _, b := range r.Blocks() {
+		start, end := max(b.Start, r.ThickStart), min(b.End, r.ThickEnd)
+		if start < end {
+			blocks = append(blocks, Block{start, end})
+		}
+	}
+	return blocks
+}
+
+// parseInts parses a comma separated list of integers, as used for
+// block sizes and starts, allowing a trailing comma
+func parseInts(s string) ([]int, error) {
+	s = strings.TrimSuffix(s, ",")
+	var values []int
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block list %q", s)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// ParseRecord parses a line of a BED file with the given number of
+// standard columns. If fields is 0, all columns up to the twelfth
+// are taken to be standard. If detail is set, the last two columns
+// are the bedDetail ID and description.
+func ParseRecord(s string, fields int, detail bool) (*Record, error) {
+	cols := strings.Split(strings.TrimRight(s, "\r\n"), "\t")
+	if detail {
+		if len(cols) < 6 {
+			return nil, fmt.Errorf("bedDetail line has %d columns", len(cols))
+		}
+		n := len(cols) - 2
+		r, err := parseColumns(cols[:n], fields)
+		if err != nil {
+			return nil, err
+		}
+		r.Detail, r.ID, r.Description = true, cols[n], cols[n+1]
+		return r, nil
+	}
+	return parseColumns(cols, fields)
+}
+
+func parseColumns(cols []string, fields int) (*Record, error) {
+	if fields == 0 {
+		fields = min(len(cols), 12)
+	}
+	if fields < 3 || fields > 12 {
+		return nil, fmt.Errorf("invalid number of BED fields %d", fields)
+	}
+	if len(cols) < fields {
+		return nil, fmt.Errorf("expected %d columns, found %d", fields, len(cols))
+	}
+	r := &Record{Chrom: cols[0], Fields: fields, Strand: "."}
+	var err error
+	if r.Start, err = strconv.Atoi(cols[1]); err != nil {
+		return nil, fmt.Errorf("invalid start %q", cols[1])
+	}
+	if r.End, err = strconv.Atoi(cols[2]); err != nil {
+		return nil, fmt.Errorf("invalid end %q", cols[2])
+	}
+	if r.Start < 0 || r.End < r.Start {
+		return nil, fmt.Errorf("invalid interval %d-%d", r.Start, r.End)
+	}
+	r.ThickStart, r.ThickEnd = r.Start, r.End
+	if fields >= 4 {
+		r.Name = cols[3]
+	}
+	if fields >= 5 && cols[4] != "." {
+		if r.Score, err = strconv.Atoi(cols[4]); err != nil {
+			return nil, fmt.Errorf("invalid score %q", cols[4])
+		}
+	}
+	if fields >= 6 {
+		switch cols[5] {
+		case "+", "-", ".":
+			r.Strand = cols[5]
+		default:
+			return nil, fmt.Errorf("invalid strand %q", cols[5])
+		}
+	}
+	if fields >= 7 {
+		if r.ThickStart, err = strconv.Atoi(cols[6]); err != nil {
+			return nil, fmt.Errorf("invalid thickStart %q", cols[6])
+		}
+		r.ThickEnd = r.End
+	}
+	if fields >= 8 {
+		if r.ThickEnd, err = strconv.Atoi(cols[7]); err != nil {
+			return nil, fmt.Errorf("invalid thickEnd %q", cols[7])
+		}
+	}
+	if fields >= 9 {
+		r.ItemRGB = cols[8]
+	}
+	if fields >= 10 {
+		if err := r.parseBlocks(cols[9:min(fields, 12)]); err != nil {
+			return nil, err
+		}
+	}
+	if len(cols) > fields {
+		r.Extra = cols[fields:]
+	}
+	return r, nil
+}
+
+// parseBlocks parses the blockCount, blockSizes and blockStarts
+// columns, of which all three must be present
+func (r *Record) parseBlocks(cols []string) error {
+	if len(cols) < 3 {
+		return fmt.Errorf("incomplete block columns")
+	}
+	count, err := strconv.Atoi(cols[0])
+	if err != nil || count < 1 {
+		return fmt.Errorf("invalid blockCount %q", cols[0])
+	}
+	if r.BlockSizes, err = parseInts(cols[1]); err != nil {
+		return err
+	}
+	if r.BlockStarts, err = parseInts(cols[2]); err != nil {
+		return err
+	}
+	if len(r.BlockSizes) != count || len(r.BlockStarts) != count {
+		return fmt.Errorf("blockCount %d does not match block lists", count)
+	}
+	for _, b := range r.Blocks() {
+		if b.Start < r.Start || b.End > r.End || b.End < b.Start {
+			return fmt.Errorf("block %d-%d outside %d-%d", b.Start, b.End, r.Start, r.End)
+		}
+	}
+	return nil
+}
+
+// minFields returns the fewest standard columns needed to
+// represent the record
+func (r *Record) minFields() int {
+	switch {
+	case len(r.BlockSizes) > 0:
+		return 12
+	case len(r.ItemRGB) > 0:
+		return 9
+	case r.ThickStart != r.Start || r.ThickEnd != r.End:
+		return 8
+	case r.Strand == "+" || r.Strand == "-":
+		return 6
+	case r.Score != 0:
+		return 5
+	case len(r.Name) > 0:
+		return 4
+	}
+	return 3
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// String returns the record as a line of a BED file, without a
+// line ending. If Fields is 0 the fewest columns needed to
+// represent the record are written.
+func (r *Record) String() string {
+	fields := r.Fields
+	if fields == 0 {
+		fields = r.minFields()
+	}
+	if fields == 10 || fields == 11 {
+		fields = 12
+	}
+	column := func(s string, missing string) string {
+		if len(s) == 0 {
+			return missing
+		}
+		return s
+	}
+	cols := []string{r.Chrom, strconv.Itoa(r.Start), strconv.Itoa(r.End),
+		column(r.Name, "."), strconv.Itoa(r.Score), column(r.Strand, "."),
+		strconv.Itoa(r.ThickStart), strconv.Itoa(r.ThickEnd), column(r.ItemRGB, "0")}
+	if fields >= 10 {
+		blocks := r.Blocks()
+		sizes, starts := make([]int, len(blocks)), make([]int, len(blocks))
+		for i, b := range blocks {
+			sizes[i], starts[i] = b.End-b.Start, b.Start-r.Start
+		}
+		cols = append(cols, strconv.Itoa(len(blocks)), joinInts(sizes), joinInts(starts))
+	}
+	cols = cols[:fields]
+	cols = append(cols, r.Extra...)
+	if r.Detail {
+		cols = append(cols, r.ID, r.Description)
+	}
+	return strings.Join(cols, "\t")
+}
+
+// ParseAll parses a BED file into its constituent records,
+// returned as a slice
+func ParseAll(r io.Reader) ([]*Record, error) {
+	return NewReader(r).ReadAll()
+}
+
+// WriteRecord writes a single bed.Record to the given io.Writer
+func WriteRecord(r *Record, w io.Writer) error {
+	_, err := io.WriteString(w, r.String()+"\n")
+	return err
+}
+
+// WriteAll writes a slice of Records to the given io.Writer
+func WriteAll(recs []*Record, w io.Writer) error {
+	bw := NewWriter(w)
+	if err := bw.WriteAll(recs); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
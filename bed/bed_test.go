@@ -0,0 +1,51 @@
+package bed
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pmagwene/biofiles/gff"
+	"github.com/pmagwene/biofiles/vcf"
+)
+
+func ExampleReader() {
+	var bedFile = `track name=genes
+chr1	999	5000	tx1	0	+	1199	4000	0	2	501,1000,	0,3001,
+chr2	100	200	peak1	500	-	100	200	255,0,0	1	100	0	12.5
+`
+	reader := NewReader(strings.NewReader(bedFile))
+	reader.Fields = 12
+	for reader.Next() {
+		rec := reader.Record()
+		fmt.Println(rec.Name, rec.Len(), rec.Blocks(), rec.ThickBlocks(), rec.Extra)
+	}
+	if err := reader.Err(); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// tx1 4001 [{999 1500} {4000 5000}] [{1199 1500}] []
+	// peak1 100 [{100 200}] [{100 200}] [12.5]
+}
+
+func ExampleFromTranscript() {
+	var gffFile = `##gff-version 3
+chr1	.	mRNA	1000	5000	.	+	.	ID=tx1
+chr1	.	exon	1000	1500	.	+	.	Parent=tx1
+chr1	.	exon	4001	5000	.	+	.	Parent=tx1
+chr1	.	CDS	1200	1500	.	+	0	Parent=tx1
+chr1	.	CDS	4001	4500	.	+	2	Parent=tx1
+`
+	recs, _ := gff.ParseAll(strings.NewReader(gffFile))
+	for _, gene := range gff.BuildGeneModels(recs) {
+		for _, t := range gene.Transcripts {
+			WriteRecord(FromTranscript(t), os.Stdout)
+		}
+	}
+
+	v, _ := vcf.ParseRecord("chr1\t1234\trs1\tAC\tA\t50\tPASS\t.")
+	WriteRecord(FromVCF(v), os.Stdout)
+	// Output:
+	// chr1	999	5000	tx1	0	+	1199	4500	0	2	501,1000	0,3001
+	// chr1	1233	1235	rs1
+}
@@ -0,0 +1,54 @@
+package bed
+
+import (
+	"github.com/pmagwene/biofiles/gff"
+	"github.com/pmagwene/biofiles/interval"
+	"github.com/pmagwene/biofiles/vcf"
+)
+
+// FromGFF returns a BED6 record spanning a GFF feature, named by
+// its ID
+func FromGFF(rec *gff.Record) *Record {
+	r := fromInterval(rec.Interval(), rec.ID)
+	r.Fields = 6
+	return r
+}
+
+// fromInterval returns a record spanning iv
+func fromInterval(iv interval.Interval, name string) *Record {
+	z := iv.In(interval.ZeroBased)
+	strand := "."
+	if z.Strand != 0 {
+		strand = string(z.Strand)
+	}
+	return &Record{Chrom: z.SeqID, Start: z.Start, End: z.End, Name: name,
+		Strand: strand, ThickStart: z.Start, ThickEnd: z.End}
+}
+
+// FromTranscript returns a BED12 record for a transcript built by
+// gff.BuildGeneModels, with a block for each exon and the thick
+// region spanning the CDS. Non-coding transcripts have an empty
+// thick region at their start, following the UCSC convention.
+func FromTranscript(t *gff.Transcript) *Record {
+	r := FromGFF(t.Record)
+	r.Fields = 12
+	for _, exon := range t.Exons {
+		r.Start, r.End = min(r.Start, exon.Start-1), max(r.End, exon.End)
+	}
+	r.ThickStart, r.ThickEnd = r.Start, r.Start
+	if len(t.Cds) > 0 {
+		r.ThickStart, r.ThickEnd = t.Cds[0].Start-1, t.Cds[len(t.Cds)-1].End
+	}
+	blocks := make([]Block, len(t.Exons))
+	for i, exon := range t.Exons {
+		blocks[i] = Block{exon.Start - 1, exon.End}
+	}
+	r.SetBlocks(blocks)
+	return r
+}
+
+// FromVCF returns a BED record spanning the reference bases of a
+// VCF record, as given by its Interval method, named by its ID
+func FromVCF(rec *vcf.Record) *Record {
+	return fromInterval(rec.Interval(), rec.ID)
+}
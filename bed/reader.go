@@ -0,0 +1,125 @@
+package bed
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// ParseError reports the location and reason of a BED parsing
+// failure. Line numbers are 1-based.
+type ParseError struct {
+	Line int
+	Text string // the offending line
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("bed: line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads BED records one at a time from an io.Reader.
+// Blank lines, comments and browser lines are skipped, as are
+// track lines, although a track line with type=bedDetail sets
+// Detail.
+//
+// Fields is the number of standard BED columns in each line, with
+// any further columns returned in Extra, as for a "bed6+4" file.
+// If Fields is 0, every column up to the twelfth is taken to be
+// standard. If Detail is set, the last two columns of each line
+// are the bedDetail ID and description.
+type Reader struct {
+	Fields int
+	Detail bool
+
+	r    *bufio.Reader
+	rec  *Record
+	err  error
+	line int
+}
+
+// NewReader returns a new Reader that reads from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next advances the Reader to the next record, which is then
+// available through Record. It returns false when there are no
+// more records, either because the end of the input was reached or
+// an error occurred; Err distinguishes the two cases.
+func (r *Reader) Next() bool {
+	r.rec = nil
+	if r.err != nil {
+		return false
+	}
+	for {
+		text, err := r.r.ReadString('\n')
+		if err != nil && (err != io.EOF || len(text) == 0) {
+			r.err = err
+			return false
+		}
+		r.line++
+		line := strings.TrimRight(text, "\r\n")
+		switch {
+		case len(strings.TrimSpace(line)) == 0, strings.HasPrefix(line, "#"),
+			strings.HasPrefix(line, "browser"):
+			continue
+		case strings.HasPrefix(line, "track"):
+			if strings.Contains(line, "type=bedDetail") {
+				r.Detail = true
+			}
+			continue
+		}
+		rec, err := ParseRecord(line, r.Fields, r.Detail)
+		if err != nil {
+			r.err = &ParseError{Line: r.line, Text: text, Err: err}
+			return false
+		}
+		r.rec = rec
+		return true
+	}
+}
+
+// Record returns the most recent record read by a call to Next
+func (r *Reader) Record() *Record {
+	return r.rec
+}
+
+// Err returns the first non-EOF error encountered by the Reader
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// ReadAll reads all the remaining records from the input
+func (r *Reader) ReadAll() ([]*Record, error) {
+	var records []*Record
+	for r.Next() {
+		records = append(records, r.Record())
+	}
+	return records, r.Err()
+}
+
+// Records returns an iterator over the remaining records in the
+// input. Iteration stops after the first error, which is yielded
+// with a nil Record.
+func (r *Reader) Records() iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		for r.Next() {
+			if !yield(r.Record(), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
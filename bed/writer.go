@@ -0,0 +1,45 @@
+package bed
+
+import (
+	"bufio"
+	"io"
+)
+
+// Writer writes BED records to an io.Writer. Output is buffered;
+// Flush must be called once all records have been written.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter returns a new Writer that writes to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// WriteTrack writes a track line with the given settings, such as
+// `name="genes" type=bedDetail`
+func (w *Writer) WriteTrack(settings string) error {
+	_, err := w.w.WriteString("track " + settings + "\n")
+	return err
+}
+
+// Write writes a single record
+func (w *Writer) Write(r *Record) error {
+	w.w.WriteString(r.String())
+	return w.w.WriteByte('\n')
+}
+
+// WriteAll writes a slice of records
+func (w *Writer) WriteAll(recs []*Record) error {
+	for _, rec := range recs {
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,154 @@
+package fasta
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Alphabet is a set of characters permitted in a sequence.
+// Membership is case-insensitive.
+type Alphabet struct {
+	Name    string
+	letters [256]bool
+}
+
+// NewAlphabet returns an Alphabet made up of the given letters,
+// in both upper and lower case
+func NewAlphabet(name string, letters string) *Alphabet {
+	a := &Alphabet{Name: name}
+	for _, c := range []byte(letters) {
+		a.letters[c] = true
+		a.letters[toUpper(c)] = true
+		a.letters[toLower(c)] = true
+	}
+	return a
+}
+
+// Standard sequence alphabets. The IUPAC variants add the ambiguity
+// codes and the '-' gap character; Protein includes '*' for stop.
+var (
+	DNA          = NewAlphabet("DNA", "ACGT")
+	DNAIUPAC     = NewAlphabet("DNA-IUPAC", "ACGTRYSWKMBDHVN-")
+	RNA          = NewAlphabet("RNA", "ACGU")
+	RNAIUPAC     = NewAlphabet("RNA-IUPAC", "ACGURYSWKMBDHVN-")
+	Protein      = NewAlphabet("protein", "ACDEFGHIKLMNPQRSTVWY*")
+	ProteinIUPAC = NewAlphabet("protein-IUPAC", "ACDEFGHIKLMNPQRSTVWYBZJXUO*-")
+)
+
+// alphabets lists the standard alphabets from most to least specific
+var alphabets = []*Alphabet{DNA, RNA, DNAIUPAC, RNAIUPAC, Protein, ProteinIUPAC}
+
+func (a *Alphabet) String() string {
+	return a.Name
+}
+
+// Contains reports whether c is in the alphabet
+func (a *Alphabet) Contains(c byte) bool {
+	return a.letters[c]
+}
+
+// Invalid returns the 0-based positions of the characters of seq
+// that are not in the alphabet
+func (a *Alphabet) Invalid(seq string) []int {
+	var pos []int
+	for i := 0; i < len(seq); i++ {
+		if !a.letters[seq[i]] {
+			pos = append(pos, i)
+		}
+	}
+	return pos
+}
+
+// Validate returns an *AlphabetError if seq contains characters
+// that are not in the alphabet
+func (a *Alphabet) Validate(seq string) error {
+	pos := a.Invalid(seq)
+	if len(pos) == 0 {
+		return nil
+	}
+	return &AlphabetError{Alphabet: a, Positions: pos, First: seq[pos[0]]}
+}
+
+// AlphabetError reports illegal characters in a sequence
+type AlphabetError struct {
+	ID        string // ID of the record, if known
+	Alphabet  *Alphabet
+	Positions []int // 0-based positions of illegal characters
+	First     byte  // the first illegal character
+}
+
+func (e *AlphabetError) Error() string {
+	var id string
+	if len(e.ID) > 0 {
+		id = " in " + e.ID
+	}
+	return fmt.Sprintf("%d characters not in %s alphabet%s, first %q at offset %d",
+		len(e.Positions), e.Alphabet, id, e.First, e.Positions[0])
+}
+
+// DetectAlphabet returns the most specific standard alphabet that
+// contains every character of seq, or nil if there is none or seq
+// is empty. Note that short nucleotide-like protein sequences will
+// be reported as DNA.
+func DetectAlphabet(seq string) *Alphabet {
+	if len(seq) == 0 {
+		return nil
+	}
+	for _, a := range alphabets {
+		if len(a.Invalid(seq)) == 0 {
+			return a
+		}
+	}
+	return nil
+}
+
+// Validate checks the sequence of the record against its Alphabet,
+// detecting and setting the Alphabet first if it is nil
+func (r *Record) Validate() error {
+	if r.Alphabet == nil {
+		r.Alphabet = DetectAlphabet(r.Sequence)
+		if r.Alphabet == nil && len(r.Sequence) > 0 {
+			return fmt.Errorf("fasta: sequence %s matches no known alphabet", r.ID)
+		}
+		return nil
+	}
+	if err := r.Alphabet.Validate(r.Sequence); err != nil {
+		err.(*AlphabetError).ID = r.ID
+		return err
+	}
+	return nil
+}
+
+// Case specifies how a Reader changes the case of sequences
+type Case int
+
+// Case options
+const (
+	PreserveCase Case = iota
+	UpperCase
+	LowerCase
+)
+
+func (c Case) apply(s string) string {
+	switch c {
+	case UpperCase:
+		return strings.ToUpper(s)
+	case LowerCase:
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+func toUpper(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
+
+func toLower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
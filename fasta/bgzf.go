@@ -0,0 +1,393 @@
+package fasta
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+	"sync"
+)
+
+/*
+BGZF is the blocked gzip format used by bgzip and samtools. A BGZF
+file is a series of gzip members ("blocks"), each holding at most
+64 KiB of uncompressed data, with the compressed size of the block
+stored in a "BC" extra subfield. Any standard gzip reader can
+decompress a BGZF file sequentially; the block structure allows
+random access through a .gzi index, which maps the compressed
+offset of each block to its uncompressed offset.
+*/
+
+const (
+	bgzfHeaderSize = 18
+	bgzfMaxBlock   = 65536
+	bgzfBlockData  = 0xff00 // uncompressed bytes per block, as in htslib
+)
+
+// bgzfEOF is the empty block that terminates a BGZF file
+var bgzfEOF = []byte{
+	0x1f, 0x8b, 0x08, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff,
+	0x06, 0x00, 0x42, 0x43, 0x02, 0x00, 0x1b, 0x00, 0x03, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+// ErrNotBGZF is returned when input is not in BGZF format
+var ErrNotBGZF = errors.New("fasta: invalid BGZF block")
+
+// VirtualOffset is a BGZF virtual file offset: the compressed offset
+// of a block in the upper 48 bits, and an offset into the
+// uncompressed data of that block in the lower 16 bits
+type VirtualOffset uint64
+
+// NewVirtualOffset builds a VirtualOffset from its two components.
+// It returns an error if either does not fit in its bits.
+func NewVirtualOffset(compressed int64, uncompressed int) (VirtualOffset, error) {
+	if compressed < 0 || compressed >= 1<<48 || uncompressed < 0 || uncompressed > 0xffff {
+		return 0, fmt.Errorf("fasta: virtual offset %d/%d out of range", compressed, uncompressed)
+	}
+	return VirtualOffset(uint64(compressed)<<16 | uint64(uncompressed)), nil
+}
+
+// Compressed returns the compressed offset of the block
+func (v VirtualOffset) Compressed() int64 {
+	return int64(v >> 16)
+}
+
+// Uncompressed returns the offset within the uncompressed block
+func (v VirtualOffset) Uncompressed() int {
+	return int(v & 0xffff)
+}
+
+// GZIEntry maps the start of a BGZF block in the compressed file
+// to the corresponding offset in the uncompressed data
+type GZIEntry struct {
+	Compressed   int64
+	Uncompressed int64
+}
+
+// GZIIndex is a .gzi index of a BGZF file. As in the file format,
+// the first block, at offsets (0, 0), is implicit and not stored.
+type GZIIndex []GZIEntry
+
+// locate returns the compressed offset of the last indexed block
+// starting at or before the uncompressed offset, and the distance
+// of offset from the start of that block, which may span several
+// blocks if the index is incomplete
+func (g GZIIndex) locate(offset int64) (int64, int64) {
+	i := sort.Search(len(g), func(i int) bool {
+		return g[i].Uncompressed > offset
+	})
+	if i == 0 {
+		return 0, offset
+	}
+	e := g[i-1]
+	return e.Compressed, offset - e.Uncompressed
+}
+
+// VirtualOffset converts an offset in the uncompressed data to a
+// virtual offset in the BGZF file. It returns an error if the index
+// does not hold the block containing offset.
+func (g GZIIndex) VirtualOffset(offset int64) (VirtualOffset, error) {
+	coffset, within := g.locate(offset)
+	if within >= bgzfMaxBlock {
+		return 0, fmt.Errorf("fasta: offset %d not in an indexed BGZF block", offset)
+	}
+	return NewVirtualOffset(coffset, int(within))
+}
+
+// ReadGZI reads a .gzi index
+func ReadGZI(r io.Reader) (GZIIndex, error) {
+	var n uint64
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, fmt.Errorf("fasta: reading gzi index: %w", err)
+	}
+	var g GZIIndex
+	var pair [2]uint64
+	for i := uint64(0); i < n; i++ {
+		if err := binary.Read(r, binary.LittleEndian, &pair); err != nil {
+			return nil, fmt.Errorf("fasta: reading gzi index: %w", err)
+		}
+		g = append(g, GZIEntry{int64(pair[0]), int64(pair[1])})
+	}
+	return g, nil
+}
+
+// WriteGZI writes g to w in .gzi format
+func WriteGZI(g GZIIndex, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := binary.Write(bw, binary.LittleEndian, uint64(len(g))); err != nil {
+		return err
+	}
+	for _, e := range g {
+		err := binary.Write(bw, binary.LittleEndian,
+			[2]uint64{uint64(e.Compressed), uint64(e.Uncompressed)})
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readBlockHeader parses a BGZF block header, returning the total
+// size of the block
+func readBlockHeader(h []byte, extra []byte) (int64, error) {
+	if h[0] != 0x1f || h[1] != 0x8b || h[2] != 8 || h[3]&4 == 0 {
+		return 0, ErrNotBGZF
+	}
+	for len(extra) >= 4 {
+		slen := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if len(extra) < 4+slen {
+			break
+		}
+		if extra[0] == 'B' && extra[1] == 'C' && slen == 2 {
+			size := int64(binary.LittleEndian.Uint16(extra[4:6])) + 1
+			// the block must hold the header, extra field, CRC32 and ISIZE
+			if size < int64(len(h)+len(extra)+8) {
+				return 0, ErrNotBGZF
+			}
+			return size, nil
+		}
+		extra = extra[4+slen:]
+	}
+	return 0, ErrNotBGZF
+}
+
+// BuildGZI scans a BGZF file and builds its .gzi index
+func BuildGZI(r io.Reader) (GZIIndex, error) {
+	var g GZIIndex
+	var coffset, uoffset int64
+	input := bufio.NewReader(r)
+	h := make([]byte, 12)
+	for {
+		if _, err := io.ReadFull(input, h); err != nil {
+			if err == io.EOF {
+				return g, nil
+			}
+			return g, fmt.Errorf("fasta: reading BGZF block: %w", err)
+		}
+		xlen := int(binary.LittleEndian.Uint16(h[10:12]))
+		extra := make([]byte, xlen)
+		if _, err := io.ReadFull(input, extra); err != nil {
+			return g, fmt.Errorf("fasta: reading BGZF block: %w", err)
+		}
+		size, err := readBlockHeader(h, extra)
+		if err != nil {
+			return g, err
+		}
+		rest := make([]byte, size-12-int64(xlen))
+		if _, err := io.ReadFull(input, rest); err != nil {
+			return g, fmt.Errorf("fasta: reading BGZF block: %w", err)
+		}
+		if coffset > 0 {
+			g = append(g, GZIEntry{coffset, uoffset})
+		}
+		coffset += size
+		uoffset += int64(binary.LittleEndian.Uint32(rest[len(rest)-4:]))
+	}
+}
+
+// BGZFReaderAt provides random access to the uncompressed contents
+// of a BGZF file. Combined with an IndexedReader it allows region
+// fetches from bgzip-compressed FASTA files:
+//
+//	NewIndexedReader(NewBGZFReaderAt(f, gzi), fai)
+type BGZFReaderAt struct {
+	r   io.ReaderAt
+	gzi GZIIndex
+
+	mu      sync.Mutex
+	coffset int64 // compressed offset of the cached block
+	size    int64 // compressed size of the cached block
+	data    []byte
+}
+
+// NewBGZFReaderAt returns a BGZFReaderAt reading the BGZF file r,
+// which is described by the index gzi
+func NewBGZFReaderAt(r io.ReaderAt, gzi GZIIndex) *BGZFReaderAt {
+	return &BGZFReaderAt{r: r, gzi: gzi, coffset: -1}
+}
+
+// readFullAt reads exactly len(p) bytes from r at off. As with
+// io.ReadFull, the error is io.EOF only if no bytes were read, and
+// io.ErrUnexpectedEOF if some but not all were.
+func readFullAt(r io.ReaderAt, p []byte, off int64) error {
+	n, err := r.ReadAt(p, off)
+	if n == len(p) {
+		return nil
+	}
+	if err == nil || err == io.EOF && n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readBlock decompresses the block at the given compressed offset,
+// returning its data and compressed size
+func (b *BGZFReaderAt) readBlock(coffset int64) ([]byte, int64, error) {
+	if coffset == b.coffset {
+		return b.data, b.size, nil
+	}
+	h := make([]byte, bgzfHeaderSize)
+	if err := readFullAt(b.r, h, coffset); err != nil {
+		return nil, 0, err
+	}
+	xlen := int64(binary.LittleEndian.Uint16(h[10:12]))
+	block := make([]byte, 12+xlen)
+	if err := readFullAt(b.r, block, coffset); err != nil {
+		return nil, 0, err
+	}
+	size, err := readBlockHeader(block[:12], block[12:])
+	if err != nil {
+		return nil, 0, err
+	}
+	block = make([]byte, size)
+	if err := readFullAt(b.r, block, coffset); err != nil {
+		return nil, 0, err
+	}
+	cdata := block[12+xlen : size-8]
+	crc := binary.LittleEndian.Uint32(block[size-8:])
+	isize := binary.LittleEndian.Uint32(block[size-4:])
+	if isize > bgzfMaxBlock {
+		return nil, 0, ErrNotBGZF
+	}
+	data := make([]byte, isize)
+	fr := flate.NewReader(bytes.NewReader(cdata))
+	defer fr.Close()
+	if _, err := io.ReadFull(fr, data); err != nil {
+		return nil, 0, fmt.Errorf("fasta: inflating BGZF block: %w", err)
+	}
+	if crc32.ChecksumIEEE(data) != crc {
+		return nil, 0, fmt.Errorf("fasta: BGZF block checksum mismatch")
+	}
+	b.coffset, b.size, b.data = coffset, size, data
+	return data, size, nil
+}
+
+// ReadAt reads len(p) bytes of uncompressed data starting at the
+// uncompressed offset off
+func (b *BGZFReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Blocks missing from the index are skipped by reading forward
+	coffset, within := b.gzi.locate(off)
+	var n int
+	for n < len(p) {
+		data, size, err := b.readBlock(coffset)
+		if err == io.EOF {
+			return n, io.EOF
+		}
+		if err != nil {
+			return n, err
+		}
+		if within >= int64(len(data)) {
+			if len(data) == 0 {
+				return n, io.EOF
+			}
+			within -= int64(len(data))
+		} else {
+			n += copy(p[n:], data[within:])
+			within = 0
+		}
+		coffset += size
+	}
+	return n, nil
+}
+
+// BGZFWriter is an io.WriteCloser that compresses its input in BGZF
+// format, recording the block offsets needed for a .gzi index.
+// Close must be called to write the final block and EOF marker.
+type BGZFWriter struct {
+	w        io.Writer
+	buf      []byte
+	cbuf     bytes.Buffer
+	fw       *flate.Writer
+	coffset  int64
+	uoffset  int64
+	index    GZIIndex
+	err      error
+	isClosed bool
+}
+
+// NewBGZFWriter returns a BGZFWriter writing to w
+func NewBGZFWriter(w io.Writer) *BGZFWriter {
+	fw, _ := flate.NewWriter(nil, flate.DefaultCompression)
+	return &BGZFWriter{w: w, fw: fw, buf: make([]byte, 0, bgzfBlockData)}
+}
+
+// Write compresses p, emitting a block each time the buffer fills
+func (b *BGZFWriter) Write(p []byte) (int, error) {
+	if b.isClosed {
+		return 0, errors.New("fasta: write to closed BGZFWriter")
+	}
+	var n int
+	for len(p) > 0 && b.err == nil {
+		k := copy(b.buf[len(b.buf):cap(b.buf)], p)
+		b.buf = b.buf[:len(b.buf)+k]
+		p = p[k:]
+		n += k
+		if len(b.buf) == cap(b.buf) {
+			b.Flush()
+		}
+	}
+	return n, b.err
+}
+
+// Flush writes any buffered data as a complete block
+func (b *BGZFWriter) Flush() error {
+	if b.err != nil || len(b.buf) == 0 {
+		return b.err
+	}
+	b.cbuf.Reset()
+	b.fw.Reset(&b.cbuf)
+	b.fw.Write(b.buf)
+	b.fw.Close()
+	if b.cbuf.Len()+26 > bgzfMaxBlock {
+		// Incompressible data; store it instead
+		b.cbuf.Reset()
+		fw, _ := flate.NewWriter(&b.cbuf, flate.NoCompression)
+		fw.Write(b.buf)
+		fw.Close()
+	}
+	size := b.cbuf.Len() + 26
+	block := make([]byte, 0, size)
+	block = append(block, bgzfEOF[:16]...)
+	block = binary.LittleEndian.AppendUint16(block, uint16(size-1))
+	block = append(block, b.cbuf.Bytes()...)
+	block = binary.LittleEndian.AppendUint32(block, crc32.ChecksumIEEE(b.buf))
+	block = binary.LittleEndian.AppendUint32(block, uint32(len(b.buf)))
+	if _, err := b.w.Write(block); err != nil {
+		b.err = err
+		return err
+	}
+	b.coffset += int64(size)
+	b.uoffset += int64(len(b.buf))
+	b.index = append(b.index, GZIEntry{b.coffset, b.uoffset})
+	b.buf = b.buf[:0]
+	return nil
+}
+
+// Close flushes any buffered data and writes the BGZF EOF marker.
+// It does not close the underlying writer.
+func (b *BGZFWriter) Close() error {
+	if b.isClosed {
+		return b.err
+	}
+	b.isClosed = true
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	if _, err := b.w.Write(bgzfEOF); err != nil {
+		b.err = err
+	}
+	return b.err
+}
+
+// Index returns the .gzi index of the blocks written so far
+func (b *BGZFWriter) Index() GZIIndex {
+	return b.index
+}
@@ -0,0 +1,299 @@
+package fasta
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pmagwene/biofiles/interval"
+)
+
+// IndexEntry is a single line of a samtools-compatible FASTA
+// index (.fai), giving the location of one sequence in the file
+type IndexEntry struct {
+	Name      string
+	Length    int64 // number of bases in the sequence
+	Offset    int64 // byte offset of the first base
+	LineBases int64 // number of bases per line
+	LineWidth int64 // number of bytes per line, including newline
+}
+
+// Index is a FASTA index, as produced by samtools faidx
+type Index struct {
+	Entries []*IndexEntry
+	names   map[string]*IndexEntry
+}
+
+func newIndex(entries []*IndexEntry) *Index {
+	idx := &Index{Entries: entries, names: make(map[string]*IndexEntry)}
+	for _, e := range entries {
+		idx.names[e.Name] = e
+	}
+	return idx
+}
+
+// Lookup returns the index entry for the named sequence
+func (idx *Index) Lookup(name string) (*IndexEntry, bool) {
+	e, ok := idx.names[name]
+	return e, ok
+}
+
+// offset returns the byte offset of the 0-based position pos
+func (e *IndexEntry) offset(pos int64) int64 {
+	return e.Offset + (pos/e.LineBases)*e.LineWidth + pos%e.LineBases
+}
+
+// BuildIndex reads a FASTA file and builds its index. As with
+// samtools faidx, every sequence line of a record except the last
+// must have the same length, and comment lines are not allowed.
+func BuildIndex(r io.Reader) (*Index, error) {
+	var entries []*IndexEntry
+	var entry *IndexEntry
+	var offset int64
+	var lineno int
+	var short bool // current record has seen its last full line
+
+	input := bufio.NewReader(r)
+	for {
+		line, err := input.ReadString('\n')
+		if len(line) == 0 {
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, &ParseError{Line: lineno + 1, Offset: offset, Err: err}
+			}
+		}
+		lineno++
+		lineOff := offset
+		offset += int64(len(line))
+		width := int64(len(line))
+		bases := int64(len(strings.TrimRight(line, "\r\n")))
+
+		if strings.HasPrefix(line, ">") {
+			rec := parseHeader(strings.TrimSpace(line))
+			if len(rec.ID) == 0 {
+				return nil, &ParseError{Line: lineno, Offset: lineOff, Err: ErrMissingID}
+			}
+			entry = &IndexEntry{Name: rec.ID, Offset: offset}
+			entries = append(entries, entry)
+			short = false
+		} else if entry == nil {
+			if bases > 0 {
+				return nil, &ParseError{Line: lineno, Offset: lineOff, Err: ErrNoHeader}
+			}
+		} else if bases > 0 {
+			switch {
+			case entry.LineBases == 0:
+				entry.LineBases = bases
+				entry.LineWidth = width
+			case short || bases > entry.LineBases:
+				return nil, &ParseError{Line: lineno, Offset: lineOff,
+					Err: fmt.Errorf("different line length in sequence %s", entry.Name)}
+			}
+			if bases < entry.LineBases || width != entry.LineWidth {
+				short = true
+			}
+			entry.Length += bases
+		} else {
+			short = true
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &ParseError{Line: lineno + 1, Offset: offset, Err: err}
+		}
+	}
+	return newIndex(entries), nil
+}
+
+// ReadIndex reads a .fai index file
+func ReadIndex(r io.Reader) (*Index, error) {
+	var entries []*IndexEntry
+	var lineno int
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("fasta: invalid index line %d", lineno)
+		}
+		var vals [4]int64
+		for i, field := range fields[1:] {
+			val, err := strconv.ParseInt(field, 10, 64)
+			if err != nil || val < 0 {
+				return nil, fmt.Errorf("fasta: invalid index line %d", lineno)
+			}
+			vals[i] = val
+		}
+		// Offsets are computed per line, so a sequence needs a
+		// positive line length that fits within the line width
+		if vals[0] > 0 && (vals[2] == 0 || vals[3] < vals[2]) {
+			return nil, fmt.Errorf("fasta: invalid index line %d", lineno)
+		}
+		entries = append(entries, &IndexEntry{Name: fields[0],
+			Length: vals[0], Offset: vals[1],
+			LineBases: vals[2], LineWidth: vals[3]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return newIndex(entries), nil
+}
+
+// WriteIndex writes idx to w in .fai format
+func WriteIndex(idx *Index, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range idx.Entries {
+		fmt.Fprintf(bw, "%s\t%d\t%d\t%d\t%d\n",
+			e.Name, e.Length, e.Offset, e.LineBases, e.LineWidth)
+	}
+	return bw.Flush()
+}
+
+// ParseRegion parses a samtools-style region string of the form
+// "name", "name:start" or "name:start-end", with 1-based inclusive
+// coordinates. Missing start and end values are returned as 0.
+func ParseRegion(s string) (name string, start int, end int, err error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return s, 0, 0, nil
+	}
+	name = s[:i]
+	span := strings.ReplaceAll(s[i+1:], ",", "")
+	startstr, endstr, hasEnd := strings.Cut(span, "-")
+	if start, err = strconv.Atoi(startstr); err != nil {
+		return name, 0, 0, fmt.Errorf("fasta: invalid region %q", s)
+	}
+	if hasEnd {
+		if end, err = strconv.Atoi(endstr); err != nil {
+			return name, 0, 0, fmt.Errorf("fasta: invalid region %q", s)
+		}
+	}
+	return name, start, end, nil
+}
+
+// IndexedReader provides random access to the sequences of an
+// indexed FASTA file
+type IndexedReader struct {
+	r   io.ReaderAt
+	idx *Index
+}
+
+// NewIndexedReader returns an IndexedReader that fetches sequences
+// from r using the given index
+func NewIndexedReader(r io.ReaderAt, idx *Index) *IndexedReader {
+	return &IndexedReader{r: r, idx: idx}
+}
+
+// Index returns the index used by the IndexedReader
+func (ir *IndexedReader) Index() *Index {
+	return ir.idx
+}
+
+// Sequence returns the complete record for the named sequence
+func (ir *IndexedReader) Sequence(name string) (*Record, error) {
+	e, ok := ir.idx.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("fasta: sequence %q not in index", name)
+	}
+	seq, err := ir.read(e, 0, e.Length)
+	if err != nil {
+		return nil, err
+	}
+	return &Record{ID: name, Sequence: seq}, nil
+}
+
+// Region returns a record holding the bases start through end
+// (1-based, inclusive) of the named sequence. An end beyond the
+// end of the sequence is truncated, as with samtools faidx. The
+// record ID is the region string, e.g. "chr1:100-200".
+func (ir *IndexedReader) Region(name string, start, end int) (*Record, error) {
+	e, ok := ir.idx.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("fasta: sequence %q not in index", name)
+	}
+	if int64(end) > e.Length {
+		end = int(e.Length)
+	}
+	if start < 1 || start > end {
+		return nil, fmt.Errorf("fasta: invalid region %s:%d-%d", name, start, end)
+	}
+	seq, err := ir.read(e, int64(start-1), int64(end))
+	if err != nil {
+		return nil, err
+	}
+	return &Record{ID: fmt.Sprintf("%s:%d-%d", name, start, end), Sequence: seq}, nil
+}
+
+// Subsequence returns a record holding the bases of iv, which may
+// be in either coordinate system, as for Region. The bases of a '-'
+// strand interval are reverse complemented, and "(-)" is appended
+// to the record ID.
+func (ir *IndexedReader) Subsequence(iv interval.Interval) (*Record, error) {
+	o := iv.In(interval.OneBased)
+	rec, err := ir.Region(o.SeqID, o.Start, o.End)
+	if err != nil {
+		return nil, err
+	}
+	if iv.Strand == '-' {
+		rec.Sequence = ReverseComplement(rec.Sequence)
+		rec.ID += "(-)"
+	}
+	return rec, nil
+}
+
+// FetchRegion returns the record for a samtools-style region
+// string, as parsed by ParseRegion
+func (ir *IndexedReader) FetchRegion(region string) (*Record, error) {
+	if _, ok := ir.idx.Lookup(region); ok {
+		return ir.Sequence(region)
+	}
+	name, start, end, err := ParseRegion(region)
+	if err != nil {
+		return nil, err
+	}
+	if start == 0 && end == 0 {
+		return ir.Sequence(name)
+	}
+	e, ok := ir.idx.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("fasta: sequence %q not in index", name)
+	}
+	if end == 0 {
+		end = int(e.Length)
+	}
+	return ir.Region(name, start, end)
+}
+
+// read returns the bases in the 0-based half-open range [start, end)
+func (ir *IndexedReader) read(e *IndexEntry, start, end int64) (string, error) {
+	if end <= start {
+		return "", nil
+	}
+	first := e.offset(start)
+	last := e.offset(end - 1)
+	buf := make([]byte, last-first+1)
+	n, err := ir.r.ReadAt(buf, first)
+	if n < len(buf) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", fmt.Errorf("fasta: reading %s: %w", e.Name, err)
+	}
+	var seq strings.Builder
+	seq.Grow(int(end - start))
+	for _, c := range buf {
+		if c != '\n' && c != '\r' {
+			seq.WriteByte(c)
+		}
+	}
+	return seq.String(), nil
+}
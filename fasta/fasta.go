@@ -1,11 +1,8 @@
 package fasta
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"io"
-	"strings"
 )
 
 // Record is a representation of a single
@@ -14,6 +11,7 @@ type Record struct {
 	ID          string
 	Description string
 	Sequence    string
+	Alphabet    *Alphabet // nil if the sequence type is unknown
 }
 
 func (r *Record) String() string {
@@ -28,78 +26,36 @@ func (r *Record) String() string {
 }
 
 // ParseAll parses a FASTA file into its
-// constituent records, returned as a slice
+// constituent records, returned as a slice.
+// Any read error is discarded; use Parse to check for errors.
 func ParseAll(r io.Reader) []*Record {
-
-	var records []*Record
-	var sequence strings.Builder
-	var currentRecord *Record = new(Record)
-
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		// Empty line
-		if len(line) == 0 {
-			continue
-		}
-		// Comment line
-		if strings.HasPrefix(line, ";") {
-			continue
-		}
-		// Start new record
-		if strings.HasPrefix(line, ">") {
-			if len(currentRecord.ID) > 0 {
-				currentRecord.Sequence = sequence.String()
-				records = append(records, currentRecord)
-			}
-			currentRecord = new(Record)
-			sequence.Reset()
-			fields := strings.Fields(line[1:])
-			currentRecord.ID = fields[0]
-			if len(fields) > 1 {
-				currentRecord.Description =
-					strings.Join(fields[1:], " ")
-			}
-		} else {
-			sequence.Grow(len(line) * 2)
-			sequence.WriteString(line)
-		}
-	}
-	// Process last record
-	if len(currentRecord.ID) > 0 {
-		currentRecord.Sequence = sequence.String()
-		records = append(records, currentRecord)
-	}
+	records, _ := Parse(r)
 	return records
 }
 
-func wrapString(s string, l int) string {
-	var ws strings.Builder
-	var i int
-	for i = 0; len(s[i:]) > l; i += l {
-		ws.WriteString(s[i : i+l])
-		ws.WriteByte('\n')
-	}
-	ws.WriteString(s[i:]) // Process last bit of string
-	return ws.String()
+// Parse parses a FASTA file into its constituent records,
+// returning the records read up to the first error. Parse is
+// lenient about malformed input; use a Reader with Strict set to
+// reject it.
+func Parse(r io.Reader) ([]*Record, error) {
+	return NewReader(r).ReadAll()
 }
 
 // WriteRecord writes string representation of single fasta.Record
-// to the given io.Writer
-func WriteRecord(r *Record, w io.Writer) {
-	var b bytes.Buffer
-	fmt.Fprintf(&b, ">%s %s\n", r.ID, r.Description)
-	b.WriteString(wrapString(r.Sequence, 80))
-	b.WriteByte('\n')
-	w.Write(b.Bytes())
+// to the given io.Writer, wrapping the sequence at DefaultLineWidth
+// characters. Use a Writer for control over the output format.
+func WriteRecord(r *Record, w io.Writer) error {
+	fw := NewWriter(w)
+	if err := fw.Write(r); err != nil {
+		return err
+	}
+	return fw.Flush()
 }
 
 // WriteAll writes string representations of slice of Records
 // to the given io.Writer
-func WriteAll(recs []*Record, w io.Writer) {
-	for _, rec := range recs {
-		WriteRecord(rec, w)
-	}
+func WriteAll(recs []*Record, w io.Writer) error {
+	return NewWriter(w).WriteAll(recs)
 }
 
 // ToMap converts sequence of fasta.Record to map of
@@ -1,17 +1,25 @@
 package fasta
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"testing/iotest"
+
+	"github.com/pmagwene/biofiles/interval"
 )
 
-func ExampleParseFasta() {
+func ExampleParseAll() {
 	var fastaExample = `>seq1 description of seq1
 	ATGCGAGATAGATCATACTGAGCTCCCTACAGGGAATCA
 	>seq2 desccription of seq2
 	ATGCCCATGGACGACTATGACCCGAGCTACTA
 	`
-	recs := ParseFasta(strings.NewReader(fastaExample))
+	recs := ParseAll(strings.NewReader(fastaExample))
 	fmt.Println(len(recs))
 	fmt.Println(recs[0].ID, recs[1].ID)
 	fmt.Println(recs[0].Description)
@@ -22,3 +30,194 @@ func ExampleParseFasta() {
 	// description of seq1
 	// ATGCCCATGGACGACTATGACCCGAGCTACTA
 }
+
+func ExampleReader() {
+	var fastaExample = `>seq1 description of seq1
+ATGCGAGATAGATCATACTGAGC
+TCCCTACAGGGAATCA
+>seq2
+ATGCCCATGGACGACTATGACCCGAGCTACTA
+`
+	reader := NewReader(strings.NewReader(fastaExample))
+	for reader.Next() {
+		rec := reader.Record()
+		fmt.Println(rec.ID, len(rec.Sequence))
+	}
+	if err := reader.Err(); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// seq1 39
+	// seq2 32
+}
+
+func ExampleReader_strict() {
+	var fastaExample = `ATGC
+>seq1
+ATGC
+`
+	reader := NewReader(strings.NewReader(fastaExample))
+	reader.Strict = true
+	_, err := reader.ReadAll()
+	fmt.Println(err)
+	// Output:
+	// fasta: line 1, byte 0: sequence data before first header
+}
+
+func ExampleReader_ioError() {
+	input := io.MultiReader(strings.NewReader(">seq1\nATGC\n>seq2\nATG"),
+		iotest.ErrReader(errors.New("connection reset")))
+	reader := NewReader(input)
+	for reader.Next() {
+		fmt.Println(reader.Record().ID)
+	}
+	fmt.Println(reader.Err())
+	// Output:
+	// seq1
+	// fasta: line 4, byte 17: connection reset
+}
+
+func ExampleIndexedReader() {
+	var fastaExample = `>chr1
+ACGTACGTAC
+GTACGTACGT
+ACG
+>chr2
+TTTTGGGGCC
+`
+	idx, _ := BuildIndex(strings.NewReader(fastaExample))
+	WriteIndex(idx, os.Stdout)
+	reader := NewIndexedReader(strings.NewReader(fastaExample), idx)
+	rec, _ := reader.FetchRegion("chr1:8-14")
+	fmt.Println(rec.ID, rec.Sequence)
+	// The same bases in 0-based half-open coordinates, minus strand
+	iv := interval.Interval{SeqID: "chr1", Start: 7, End: 14, Strand: '-', Coords: interval.ZeroBased}
+	rec, _ = reader.Subsequence(iv)
+	fmt.Println(rec.ID, rec.Sequence)
+	// Output:
+	// chr1	23	6	10	11
+	// chr2	10	38	10	11
+	// chr1:8-14 TACGTAC
+	// chr1:8-14(-) GTACGTA
+}
+
+func ExampleBGZFWriter() {
+	recs := []*Record{
+		{ID: "chr1", Sequence: strings.Repeat("ACGT", 50000)},
+		{ID: "chr2", Sequence: strings.Repeat("GGCC", 50000)},
+	}
+	var compressed bytes.Buffer
+	bw := NewBGZFWriter(&compressed)
+	WriteAll(recs, bw)
+	bw.Close()
+
+	// The .fai index uses offsets into the uncompressed data
+	gz, _ := gzip.NewReader(bytes.NewReader(compressed.Bytes()))
+	fai, _ := BuildIndex(gz)
+	gzi, _ := BuildGZI(bytes.NewReader(compressed.Bytes()))
+
+	data := NewBGZFReaderAt(bytes.NewReader(compressed.Bytes()), gzi)
+	reader := NewIndexedReader(data, fai)
+	rec, _ := reader.Region("chr2", 199995, 200002)
+	fmt.Println(rec.ID, rec.Sequence)
+	vo, _ := gzi.VirtualOffset(400000)
+	fmt.Println(vo.Compressed() > 0, vo.Uncompressed())
+
+	// Without a .gzi index, blocks are found by reading forward
+	reader = NewIndexedReader(NewBGZFReaderAt(bytes.NewReader(compressed.Bytes()), nil), fai)
+	rec, _ = reader.Region("chr2", 199995, 200002)
+	fmt.Println(rec.ID, rec.Sequence)
+	_, err := GZIIndex(nil).VirtualOffset(400000)
+	fmt.Println(err)
+	// Output:
+	// chr2:199995-200000 CCGGCC
+	// true 8320
+	// chr2:199995-200000 CCGGCC
+	// fasta: offset 400000 not in an indexed BGZF block
+}
+
+func ExampleWriter() {
+	recs := []*Record{
+		{ID: "seq1", Description: "first sequence", Sequence: "ATGCGAGATAGATCATAC"},
+		{ID: "seq2", Sequence: "ATGCCCATGG"},
+	}
+	w := NewWriter(os.Stdout)
+	w.LineWidth = 8
+	w.WriteAll(recs)
+	// Output:
+	// >seq1 first sequence
+	// ATGCGAGA
+	// TAGATCAT
+	// AC
+	// >seq2
+	// ATGCCCAT
+	// GG
+}
+
+func ExampleAlphabet() {
+	fmt.Println(DetectAlphabet("ACGTTGCA"), DetectAlphabet("ACGUNNRY"),
+		DetectAlphabet("MKVLAAGIW"))
+	fmt.Println(DNA.Invalid("ACGTNACXT"))
+
+	var fastaExample = `>seq1
+acgtac
+gtNN
+`
+	reader := NewReader(strings.NewReader(fastaExample))
+	reader.Alphabet = DNAIUPAC
+	reader.Case = UpperCase
+	recs, _ := reader.ReadAll()
+	fmt.Println(recs[0].Sequence, recs[0].Alphabet)
+	// Output:
+	// DNA RNA-IUPAC protein
+	// [4 7]
+	// ACGTACGTNN DNA-IUPAC
+}
+
+func ExampleTranslate() {
+	rec := &Record{ID: "seq1", Sequence: "TTGAAAGCRTGGTAATTT"}
+	fmt.Println(rec.ReverseComplement().Sequence)
+	fmt.Println(rec.Transcribe().Sequence)
+	prot, _ := rec.Translate(TranslateOptions{Table: 11, Start: true, ToStop: true})
+	fmt.Println(prot.ID, prot.Sequence)
+	prot, _ = rec.Translate(TranslateOptions{Frame: -1})
+	fmt.Println(prot.ID, prot.Sequence)
+	// Output:
+	// AAATTACCAYGCTTTCAA
+	// UUGAAAGCRUGGUAAUUU
+	// seq1 MKAW
+	// seq1_frame-1 KLPXFQ
+}
+
+func ExampleGeneticCodes() {
+	for _, g := range GeneticCodes() {
+		fmt.Println(g.ID, g.Name, string(g.Codon("TAG")))
+	}
+	// Output:
+	// 1 Standard *
+	// 2 Vertebrate Mitochondrial *
+	// 3 Yeast Mitochondrial *
+	// 4 Mold, Protozoan, and Coelenterate Mitochondrial and Mycoplasma/Spiroplasma *
+	// 5 Invertebrate Mitochondrial *
+	// 6 Ciliate, Dasycladacean and Hexamita Nuclear Q
+	// 9 Echinoderm and Flatworm Mitochondrial *
+	// 10 Euplotid Nuclear *
+	// 11 Bacterial, Archaeal and Plant Plastid *
+	// 12 Alternative Yeast Nuclear *
+	// 13 Ascidian Mitochondrial *
+	// 14 Alternative Flatworm Mitochondrial *
+	// 16 Chlorophycean Mitochondrial L
+	// 21 Trematode Mitochondrial *
+	// 22 Scenedesmus obliquus Mitochondrial L
+	// 23 Thraustochytrium Mitochondrial *
+	// 24 Rhabdopleuridae Mitochondrial *
+	// 25 Candidate Division SR1 and Gracilibacteria *
+	// 26 Pachysolen tannophilus Nuclear *
+	// 27 Karyorelict Nuclear Q
+	// 28 Condylostoma Nuclear Q
+	// 29 Mesodinium Nuclear Y
+	// 30 Peritrich Nuclear E
+	// 31 Blastocrithidia Nuclear E
+	// 32 Balanophoraceae Plastid W
+	// 33 Cephalodiscidae Mitochondrial *
+}
@@ -0,0 +1,208 @@
+package fasta
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GeneticCode is an NCBI genetic code table. AminoAcids holds the
+// translation of the 64 codons in NCBI order, with the bases of
+// each codon position varying in the order TCAG.
+type GeneticCode struct {
+	ID         int
+	Name       string
+	AminoAcids string
+	Starts     []string // codons that may initiate translation
+	starts     [64]bool
+}
+
+// The NCBI genetic codes, from
+// https://www.ncbi.nlm.nih.gov/Taxonomy/Utils/wprintgc.cgi
+var geneticCodes = map[int]*GeneticCode{}
+
+func init() {
+	for _, g := range []*GeneticCode{
+		{ID: 1, Name: "Standard",
+			AminoAcids: "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTG", "CTG", "ATG"}},
+		{ID: 2, Name: "Vertebrate Mitochondrial",
+			AminoAcids: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSS**VVVVAAAADDEEGGGG",
+			Starts:     []string{"ATT", "ATC", "ATA", "ATG", "GTG"}},
+		{ID: 3, Name: "Yeast Mitochondrial",
+			AminoAcids: "FFLLSSSSYY**CCWWTTTTPPPPHHQQRRRRIIMMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATA", "ATG"}},
+		{ID: 4, Name: "Mold, Protozoan, and Coelenterate Mitochondrial and Mycoplasma/Spiroplasma",
+			AminoAcids: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTA", "TTG", "CTG", "ATT", "ATC", "ATA", "ATG", "GTG"}},
+		{ID: 5, Name: "Invertebrate Mitochondrial",
+			AminoAcids: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSSSVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTG", "ATT", "ATC", "ATA", "ATG", "GTG"}},
+		{ID: 6, Name: "Ciliate, Dasycladacean and Hexamita Nuclear",
+			AminoAcids: "FFLLSSSSYYQQCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 9, Name: "Echinoderm and Flatworm Mitochondrial",
+			AminoAcids: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG", "GTG"}},
+		{ID: 10, Name: "Euplotid Nuclear",
+			AminoAcids: "FFLLSSSSYY**CCCWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 11, Name: "Bacterial, Archaeal and Plant Plastid",
+			AminoAcids: "FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTG", "CTG", "ATT", "ATC", "ATA", "ATG", "GTG"}},
+		{ID: 12, Name: "Alternative Yeast Nuclear",
+			AminoAcids: "FFLLSSSSYY**CC*WLLLSPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"CTG", "ATG"}},
+		{ID: 13, Name: "Ascidian Mitochondrial",
+			AminoAcids: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNKKSSGGVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTG", "ATA", "ATG", "GTG"}},
+		{ID: 14, Name: "Alternative Flatworm Mitochondrial",
+			AminoAcids: "FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 16, Name: "Chlorophycean Mitochondrial",
+			AminoAcids: "FFLLSSSSYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 21, Name: "Trematode Mitochondrial",
+			AminoAcids: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIMMTTTTNNNKSSSSVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG", "GTG"}},
+		{ID: 22, Name: "Scenedesmus obliquus Mitochondrial",
+			AminoAcids: "FFLLSS*SYY*LCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 23, Name: "Thraustochytrium Mitochondrial",
+			AminoAcids: "FF*LSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATT", "ATG", "GTG"}},
+		{ID: 24, Name: "Rhabdopleuridae Mitochondrial",
+			AminoAcids: "FFLLSSSSYY**CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTG", "CTG", "ATG", "GTG"}},
+		{ID: 25, Name: "Candidate Division SR1 and Gracilibacteria",
+			AminoAcids: "FFLLSSSSYY**CCGWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTG", "ATG", "GTG"}},
+		{ID: 26, Name: "Pachysolen tannophilus Nuclear",
+			AminoAcids: "FFLLSSSSYY**CC*WLLLAPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"CTG", "ATG"}},
+		{ID: 27, Name: "Karyorelict Nuclear",
+			AminoAcids: "FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 28, Name: "Condylostoma Nuclear",
+			AminoAcids: "FFLLSSSSYYQQCCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 29, Name: "Mesodinium Nuclear",
+			AminoAcids: "FFLLSSSSYYYYCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 30, Name: "Peritrich Nuclear",
+			AminoAcids: "FFLLSSSSYYEECC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 31, Name: "Blastocrithidia Nuclear",
+			AminoAcids: "FFLLSSSSYYEECCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"ATG"}},
+		{ID: 32, Name: "Balanophoraceae Plastid",
+			AminoAcids: "FFLLSSSSYY*WCC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTG", "CTG", "ATT", "ATC", "ATA", "ATG", "GTG"}},
+		{ID: 33, Name: "Cephalodiscidae Mitochondrial",
+			AminoAcids: "FFLLSSSSYYY*CCWWLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSSKVVVVAAAADDEEGGGG",
+			Starts:     []string{"TTG", "CTG", "ATG", "GTG"}},
+	} {
+		for _, codon := range g.Starts {
+			i, _ := codonIndex(codon[0], codon[1], codon[2])
+			g.starts[i] = true
+		}
+		geneticCodes[g.ID] = g
+	}
+}
+
+// LookupGeneticCode returns the NCBI genetic code with the given
+// table ID
+func LookupGeneticCode(id int) (*GeneticCode, error) {
+	g, ok := geneticCodes[id]
+	if !ok {
+		return nil, fmt.Errorf("fasta: unknown genetic code %d", id)
+	}
+	return g, nil
+}
+
+// GeneticCodes returns all the NCBI genetic codes, ordered by ID
+func GeneticCodes() []*GeneticCode {
+	var codes []*GeneticCode
+	for _, g := range geneticCodes {
+		codes = append(codes, g)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].ID < codes[j].ID })
+	return codes
+}
+
+// baseIndex gives the position of each unambiguous base in TCAG order
+func baseIndex(c byte) (int, bool) {
+	switch toUpper(c) {
+	case 'T', 'U':
+		return 0, true
+	case 'C':
+		return 1, true
+	case 'A':
+		return 2, true
+	case 'G':
+		return 3, true
+	}
+	return 0, false
+}
+
+// codonIndex returns the index of an unambiguous codon in the
+// AminoAcids table
+func codonIndex(b1, b2, b3 byte) (int, bool) {
+	i1, ok1 := baseIndex(b1)
+	i2, ok2 := baseIndex(b2)
+	i3, ok3 := baseIndex(b3)
+	return i1*16 + i2*4 + i3, ok1 && ok2 && ok3
+}
+
+// iupacBases gives the unambiguous bases represented by each
+// IUPAC nucleotide code
+var iupacBases = map[byte]string{
+	'A': "A", 'C': "C", 'G': "G", 'T': "T", 'U': "T",
+	'R': "AG", 'Y': "CT", 'S': "CG", 'W': "AT", 'K': "GT", 'M': "AC",
+	'B': "CGT", 'D': "AGT", 'H': "ACT", 'V': "ACG", 'N': "ACGT",
+}
+
+// Codon returns the amino acid encoded by a three letter codon.
+// Ambiguous codons are resolved if every codon they represent
+// encodes the same amino acid, and are otherwise translated as 'X'.
+// A gap codon "---" is translated as '-'.
+func (g *GeneticCode) Codon(codon string) byte {
+	if len(codon) != 3 {
+		return 'X'
+	}
+	if i, ok := codonIndex(codon[0], codon[1], codon[2]); ok {
+		return g.AminoAcids[i]
+	}
+	if codon == "---" {
+		return '-'
+	}
+	var aa byte
+	for _, b1 := range []byte(iupacBases[toUpper(codon[0])]) {
+		for _, b2 := range []byte(iupacBases[toUpper(codon[1])]) {
+			for _, b3 := range []byte(iupacBases[toUpper(codon[2])]) {
+				i, _ := codonIndex(b1, b2, b3)
+				if aa != 0 && aa != g.AminoAcids[i] {
+					return 'X'
+				}
+				aa = g.AminoAcids[i]
+			}
+		}
+	}
+	if aa == 0 {
+		return 'X'
+	}
+	return aa
+}
+
+// IsStart reports whether codon is a start codon of the code
+func (g *GeneticCode) IsStart(codon string) bool {
+	if len(codon) != 3 {
+		return false
+	}
+	i, ok := codonIndex(codon[0], codon[1], codon[2])
+	return ok && g.starts[i]
+}
+
+// IsStop reports whether codon is a stop codon of the code
+func (g *GeneticCode) IsStop(codon string) bool {
+	return g.Codon(codon) == '*'
+}
@@ -0,0 +1,219 @@
+package fasta
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// Errors describing malformed FASTA input, wrapped in a ParseError
+var (
+	ErrMissingID = errors.New("header has no sequence ID")
+	ErrNoHeader  = errors.New("sequence data before first header")
+)
+
+// ParseError reports the location and reason of a FASTA parsing
+// failure. Line numbers are 1-based; Offset is the byte offset of
+// the start of the offending line.
+type ParseError struct {
+	Line   int
+	Offset int64
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("fasta: line %d, byte %d: %v", e.Line, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads FASTA records one at a time from an io.Reader.
+// Memory use is bounded by the size of the largest single record,
+// so arbitrarily large files can be processed.
+//
+// By default the Reader is lenient: headers without an ID and
+// sequence data preceding the first header are skipped. If Strict
+// is set, these are reported as a *ParseError instead.
+//
+// If Alphabet is set, each sequence is validated against it and
+// any illegal characters are reported as a *ParseError wrapping an
+// *AlphabetError. Case controls the case of returned sequences.
+type Reader struct {
+	Strict   bool
+	Alphabet *Alphabet
+	Case     Case
+
+	r          *bufio.Reader
+	header     string // header line of the next record, if already read
+	headerLine int
+	headerOff  int64
+	rec        *Record
+	err        error
+	line       int   // number of lines read
+	offset     int64 // number of bytes read
+	lineOff    int64 // offset of the start of the current line
+}
+
+// NewReader returns a new Reader that reads from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// readLine returns the next line of input, without any length limit
+func (r *Reader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil && err != io.EOF {
+		return "", &ParseError{Line: r.line + 1, Offset: r.offset, Err: err}
+	}
+	if len(line) > 0 {
+		r.line++
+		r.lineOff = r.offset
+		r.offset += int64(len(line))
+	}
+	return line, err
+}
+
+// error returns a *ParseError for the current line
+func (r *Reader) error(err error) error {
+	return &ParseError{Line: r.line, Offset: r.lineOff, Err: err}
+}
+
+// parseHeader builds a new Record from a '>' header line
+func parseHeader(line string) *Record {
+	rec := new(Record)
+	fields := strings.Fields(line[1:])
+	if len(fields) > 0 {
+		rec.ID = fields[0]
+	}
+	if len(fields) > 1 {
+		rec.Description = strings.Join(fields[1:], " ")
+	}
+	return rec
+}
+
+// Next advances the Reader to the next record, which is then
+// available through Record. It returns false when there are no
+// more records, either because the end of the input was reached or
+// an error occurred; Err distinguishes the two cases.
+func (r *Reader) Next() bool {
+	r.rec = nil
+	if r.err != nil {
+		return false
+	}
+	var rec *Record
+	var recLine int
+	var recOff int64
+	var sequence strings.Builder
+	if r.header != "" {
+		rec = parseHeader(r.header)
+		recLine, recOff = r.headerLine, r.headerOff
+		r.header = ""
+	}
+	if rec != nil && len(rec.ID) == 0 && r.Strict {
+		r.err = &ParseError{Line: recLine, Offset: recOff, Err: ErrMissingID}
+		return false
+	}
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			r.err = err
+			if err != io.EOF {
+				// a partially read record is discarded
+				return false
+			}
+			break
+		}
+		line = strings.TrimSpace(line)
+		// Empty line or comment line
+		if len(line) == 0 || strings.HasPrefix(line, ";") {
+			continue
+		}
+		// Start of a new record
+		if strings.HasPrefix(line, ">") {
+			if rec != nil && len(rec.ID) > 0 {
+				r.header = line
+				r.headerLine = r.line
+				r.headerOff = r.lineOff
+				break
+			}
+			rec = parseHeader(line)
+			recLine, recOff = r.line, r.lineOff
+			if len(rec.ID) == 0 && r.Strict {
+				r.err = r.error(ErrMissingID)
+				return false
+			}
+			sequence.Reset()
+			continue
+		}
+		// Sequence data before the first header is ignored
+		// unless in strict mode
+		if rec == nil {
+			if r.Strict {
+				r.err = r.error(ErrNoHeader)
+				return false
+			}
+			continue
+		}
+		sequence.WriteString(r.Case.apply(line))
+	}
+	if rec == nil || len(rec.ID) == 0 {
+		return false
+	}
+	rec.Sequence = sequence.String()
+	if r.Alphabet != nil {
+		if err := r.Alphabet.Validate(rec.Sequence); err != nil {
+			err.(*AlphabetError).ID = rec.ID
+			r.err = &ParseError{Line: recLine, Offset: recOff, Err: err}
+			return false
+		}
+		rec.Alphabet = r.Alphabet
+	}
+	r.rec = rec
+	return true
+}
+
+// Record returns the most recent record read by a call to Next
+func (r *Reader) Record() *Record {
+	return r.rec
+}
+
+// Err returns the first non-EOF error encountered by the Reader
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// ReadAll reads all the remaining records from the input
+func (r *Reader) ReadAll() ([]*Record, error) {
+	var records []*Record
+	for r.Next() {
+		records = append(records, r.Record())
+	}
+	return records, r.Err()
+}
+
+// Records returns an iterator over the remaining records in the
+// input. Iteration stops after the first error, which is yielded
+// with a nil Record.
+func (r *Reader) Records() iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		for r.Next() {
+			if !yield(r.Record(), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
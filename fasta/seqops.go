@@ -0,0 +1,195 @@
+package fasta
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmagwene/biofiles/interval"
+)
+
+// complements maps each IUPAC nucleotide code to its complement,
+// preserving case. Characters without a complement map to themselves.
+var complements [256]byte
+
+func init() {
+	for i := range complements {
+		complements[i] = byte(i)
+	}
+	for _, pair := range []string{"AT", "CG", "RY", "KM", "BV", "DH", "SS", "WW", "NN"} {
+		a, b := pair[0], pair[1]
+		complements[a], complements[b] = b, a
+		complements[toLower(a)], complements[toLower(b)] = toLower(b), toLower(a)
+	}
+	complements['U'], complements['u'] = 'A', 'a'
+}
+
+// isRNA reports whether a nucleotide sequence uses U rather than T
+func isRNA(seq string) bool {
+	return strings.ContainsAny(seq, "Uu") && !strings.ContainsAny(seq, "Tt")
+}
+
+// ReverseComplement returns the reverse complement of a DNA or RNA
+// sequence, including IUPAC ambiguity codes. If seq contains U but
+// no T it is treated as RNA, so that A is complemented to U.
+func ReverseComplement(seq string) string {
+	rna := isRNA(seq)
+	rc := make([]byte, len(seq))
+	for i := 0; i < len(seq); i++ {
+		c := complements[seq[i]]
+		if rna {
+			switch c {
+			case 'T':
+				c = 'U'
+			case 't':
+				c = 'u'
+			}
+		}
+		rc[len(seq)-1-i] = c
+	}
+	return string(rc)
+}
+
+// Transcribe converts a DNA sequence to RNA, replacing T with U
+func Transcribe(seq string) string {
+	return strings.NewReplacer("T", "U", "t", "u").Replace(seq)
+}
+
+// BackTranscribe converts an RNA sequence to DNA, replacing U with T
+func BackTranscribe(seq string) string {
+	return strings.NewReplacer("U", "T", "u", "t").Replace(seq)
+}
+
+// TranslateOptions controls the translation of nucleotide sequences
+type TranslateOptions struct {
+	// Table is the NCBI genetic code; 0 selects the standard code
+	Table int
+
+	// Frame is the reading frame: 1, 2 or 3 on the given strand,
+	// or -1, -2 or -3 on the reverse complement; 0 selects frame 1
+	Frame int
+
+	// Start translates the first codon as methionine when it is an
+	// alternative start codon of the genetic code
+	Start bool
+
+	// ToStop ends translation at the first stop codon, which is
+	// not included in the result
+	ToStop bool
+}
+
+// Translate translates a nucleotide sequence to protein. Stop
+// codons are translated as '*' and a trailing partial codon is
+// ignored.
+func Translate(seq string, opts TranslateOptions) (string, error) {
+	table := opts.Table
+	if table == 0 {
+		table = 1
+	}
+	code, err := LookupGeneticCode(table)
+	if err != nil {
+		return "", err
+	}
+	frame := opts.Frame
+	if frame == 0 {
+		frame = 1
+	}
+	if frame < -3 || frame > 3 {
+		return "", fmt.Errorf("fasta: invalid reading frame %d", frame)
+	}
+	if frame < 0 {
+		seq = ReverseComplement(seq)
+		frame = -frame
+	}
+	seq = seq[min(frame-1, len(seq)):]
+
+	var protein strings.Builder
+	protein.Grow(len(seq) / 3)
+	for i := 0; i+3 <= len(seq); i += 3 {
+		codon := seq[i : i+3]
+		aa := code.Codon(codon)
+		if i == 0 && opts.Start && code.IsStart(codon) {
+			aa = 'M'
+		}
+		if aa == '*' && opts.ToStop {
+			break
+		}
+		protein.WriteByte(aa)
+	}
+	return protein.String(), nil
+}
+
+// ReverseComplement returns a new Record holding the reverse
+// complement of the sequence, with "_rc" appended to the ID
+func (r *Record) ReverseComplement() *Record {
+	return &Record{
+		ID:          r.ID + "_rc",
+		Description: r.Description,
+		Sequence:    ReverseComplement(r.Sequence),
+		Alphabet:    r.Alphabet,
+	}
+}
+
+// Transcribe returns a new Record holding the RNA transcript of a
+// DNA sequence
+func (r *Record) Transcribe() *Record {
+	rec := &Record{ID: r.ID, Description: r.Description,
+		Sequence: Transcribe(r.Sequence)}
+	switch r.Alphabet {
+	case DNA:
+		rec.Alphabet = RNA
+	case DNAIUPAC:
+		rec.Alphabet = RNAIUPAC
+	}
+	return rec
+}
+
+// BackTranscribe returns a new Record holding the DNA equivalent
+// of an RNA sequence
+func (r *Record) BackTranscribe() *Record {
+	rec := &Record{ID: r.ID, Description: r.Description,
+		Sequence: BackTranscribe(r.Sequence)}
+	switch r.Alphabet {
+	case RNA:
+		rec.Alphabet = DNA
+	case RNAIUPAC:
+		rec.Alphabet = DNAIUPAC
+	}
+	return rec
+}
+
+// Translate returns a new Record holding the protein translation of
+// the sequence. When translating a frame other than 1 the frame is
+// appended to the ID, e.g. "seq1_frame-2".
+func (r *Record) Translate(opts TranslateOptions) (*Record, error) {
+	protein, err := Translate(r.Sequence, opts)
+	if err != nil {
+		return nil, err
+	}
+	id := r.ID
+	if opts.Frame != 0 && opts.Frame != 1 {
+		id = fmt.Sprintf("%s_frame%d", r.ID, opts.Frame)
+	}
+	return &Record{ID: id, Description: r.Description,
+		Sequence: protein, Alphabet: ProteinIUPAC}, nil
+}
+
+// Subsequence returns a new record holding the bases of r within
+// iv, which may be in either coordinate system; the SeqID of iv is
+// ignored. An end beyond the end of the sequence is truncated, and
+// the bases of a '-' strand interval are reverse complemented. The
+// record ID is the 1-based region, e.g. "chr1:100-200(-)".
+func (r *Record) Subsequence(iv interval.Interval) (*Record, error) {
+	iv.SeqID = r.ID
+	z := iv.In(interval.ZeroBased)
+	z.End = min(z.End, len(r.Sequence))
+	if z.Start < 0 || z.Start >= z.End {
+		return nil, fmt.Errorf("fasta: invalid interval %s", iv)
+	}
+	id := fmt.Sprintf("%s:%d-%d", r.ID, z.Start+1, z.End)
+	seq := r.Sequence[z.Start:z.End]
+	if iv.Strand == '-' {
+		id += "(-)"
+		seq = ReverseComplement(seq)
+	}
+	return &Record{ID: id, Sequence: seq, Alphabet: r.Alphabet}, nil
+}
@@ -0,0 +1,74 @@
+package fasta
+
+import (
+	"bufio"
+	"io"
+)
+
+// DefaultLineWidth is the sequence line width used by NewWriter
+const DefaultLineWidth = 80
+
+// Writer writes FASTA records to an io.Writer. Output is buffered;
+// Flush must be called once all records have been written.
+type Writer struct {
+	// LineWidth is the number of sequence characters per line.
+	// If LineWidth is 0 each sequence is written on a single line.
+	LineWidth int
+
+	// Header, if non-nil, returns the header line of a record,
+	// without the leading '>'. By default the header is the ID,
+	// followed by the Description if one is present.
+	Header func(*Record) string
+
+	w *bufio.Writer
+}
+
+// NewWriter returns a new Writer that writes to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{LineWidth: DefaultLineWidth, w: bufio.NewWriter(w)}
+}
+
+// DefaultHeader returns the default header line of a record
+func DefaultHeader(r *Record) string {
+	if len(r.Description) == 0 {
+		return r.ID
+	}
+	return r.ID + " " + r.Description
+}
+
+// Write writes a single record
+func (w *Writer) Write(r *Record) error {
+	header := DefaultHeader
+	if w.Header != nil {
+		header = w.Header
+	}
+	w.w.WriteByte('>')
+	w.w.WriteString(header(r))
+	w.w.WriteByte('\n')
+	seq := r.Sequence
+	if w.LineWidth > 0 {
+		for len(seq) > w.LineWidth {
+			w.w.WriteString(seq[:w.LineWidth])
+			w.w.WriteByte('\n')
+			seq = seq[w.LineWidth:]
+		}
+	}
+	w.w.WriteString(seq)
+	_, err := w.w.WriteString("\n")
+	return err
+}
+
+// WriteAll writes a slice of records and flushes the Writer
+func (w *Writer) WriteAll(recs []*Record) error {
+	for _, rec := range recs {
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,69 @@
+// Package fastq reads and writes FASTQ formatted sequencing reads
+package fastq
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Record is a representation of a single
+// FASTQ formatted sequence record
+type Record struct {
+	ID          string
+	Description string
+	Sequence    string
+	Quality     string
+}
+
+func (r *Record) String() string {
+	i := len(r.Sequence)
+	fmtstr := "@%s %s\n%s\n+\n%s\n"
+	if i > 70 {
+		i = 70
+		fmtstr = "@%s %s\n%s...\n+\n%s...\n"
+	}
+	j := min(i, len(r.Quality))
+	return fmt.Sprintf(fmtstr,
+		r.ID, r.Description, r.Sequence[:i], r.Quality[:j])
+}
+
+// Scores decodes the quality string of the record to Phred
+// quality scores using the given encoding
+func (r *Record) Scores(e Encoding) ([]int, error) {
+	return e.Decode(r.Quality)
+}
+
+// ParseAll parses a FASTQ file into its constituent
+// records, returned as a slice
+func ParseAll(r io.Reader) ([]*Record, error) {
+	return NewReader(r).ReadAll()
+}
+
+// WriteRecord writes a single fastq.Record to the given io.Writer.
+// The sequence and quality are each written on a single line.
+func WriteRecord(r *Record, w io.Writer) error {
+	if len(r.Sequence) != len(r.Quality) {
+		return fmt.Errorf("fastq: record %s: %w", r.ID, ErrLengthMismatch)
+	}
+	var err error
+	if len(r.Description) > 0 {
+		_, err = fmt.Fprintf(w, "@%s %s\n%s\n+\n%s\n",
+			r.ID, r.Description, r.Sequence, r.Quality)
+	} else {
+		_, err = fmt.Fprintf(w, "@%s\n%s\n+\n%s\n",
+			r.ID, r.Sequence, r.Quality)
+	}
+	return err
+}
+
+// WriteAll writes a slice of Records to the given io.Writer
+func WriteAll(recs []*Record, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, rec := range recs {
+		if err := WriteRecord(rec, bw); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,83 @@
+package fastq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing/iotest"
+)
+
+func ExampleParseAll() {
+	var fastqExample = `@read1 sample=A
+ACGTTGCA
+GGTA
++
+IIIIHHHH
+@@@@
+@read2
+ACGT
++read2
+!!5J
+`
+	recs, err := ParseAll(strings.NewReader(fastqExample))
+	fmt.Println(len(recs), err)
+	fmt.Println(recs[0].ID, recs[0].Description, recs[0].Sequence)
+	enc, _ := DetectEncoding(recs)
+	scores, _ := recs[1].Scores(enc)
+	fmt.Println(enc, scores)
+	WriteAll(recs[1:], os.Stdout)
+	// Output:
+	// 2 <nil>
+	// read1 sample=A ACGTTGCAGGTA
+	// Phred+33 [0 0 20 41]
+	// @read2
+	// ACGT
+	// +
+	// !!5J
+}
+
+func ExampleDetectEncoding() {
+	for _, qual := range []string{"", "FFF:F,FF", "@@@FFFJJ", "BBBhhhe]", "!!5J", "?hhh", ";<=hh"} {
+		enc, _ := DetectEncoding([]*Record{{ID: "r", Quality: qual}})
+		scores, err := enc.Decode(qual)
+		fmt.Printf("%q %v %v %v\n", qual, enc, scores, err)
+	}
+	// Output:
+	// "" Phred+33 [] <nil>
+	// "FFF:F,FF" Phred+33 [37 37 37 25 37 11 37 37] <nil>
+	// "@@@FFFJJ" Phred+33 [31 31 31 37 37 37 41 41] <nil>
+	// "BBBhhhe]" Phred+64 [2 2 2 40 40 40 37 29] <nil>
+	// "!!5J" Phred+33 [0 0 20 41] <nil>
+	// "?hhh" Phred+33 [30 71 71 71] <nil>
+	// ";<=hh" Phred+33 [26 27 28 71 71] <nil>
+}
+
+func ExampleReader() {
+	var fastqExample = `@read1
+ACGTACGT
++
+IIII
+`
+	reader := NewReader(strings.NewReader(fastqExample))
+	for reader.Next() {
+		fmt.Println(reader.Record().ID)
+	}
+	fmt.Println(reader.Err())
+	// Output:
+	// fastq: line 4, byte 18: sequence and quality lengths differ
+}
+
+func ExampleReader_ioError() {
+	input := io.MultiReader(strings.NewReader("@read1\nACGT\n+\nIIII\n@read2\nAC"),
+		iotest.ErrReader(errors.New("connection reset")))
+	reader := NewReader(input)
+	for reader.Next() {
+		fmt.Println(reader.Record().ID)
+	}
+	fmt.Println(reader.Err())
+	// Output:
+	// read1
+	// fastq: line 6, byte 26: connection reset
+}
@@ -0,0 +1,90 @@
+package fastq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Encoding is the ASCII offset scheme of FASTQ quality strings
+type Encoding int
+
+// Quality encodings
+const (
+	Phred33 Encoding = iota // Sanger, Illumina 1.8+
+	Phred64                 // Illumina 1.3 to 1.7
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case Phred33:
+		return "Phred+33"
+	case Phred64:
+		return "Phred+64"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// Offset returns the ASCII value corresponding to quality 0
+func (e Encoding) Offset() int {
+	if e == Phred64 {
+		return 64
+	}
+	return 33
+}
+
+// Decode converts a quality string to Phred quality scores
+func (e Encoding) Decode(qual string) ([]int, error) {
+	offset := e.Offset()
+	scores := make([]int, len(qual))
+	for i := 0; i < len(qual); i++ {
+		c := int(qual[i])
+		if c < offset || c > '~' {
+			return scores, fmt.Errorf("fastq: invalid %s quality %q at position %d",
+				e, qual[i], i)
+		}
+		scores[i] = c - offset
+	}
+	return scores, nil
+}
+
+// Encode converts Phred quality scores to a quality string
+func (e Encoding) Encode(scores []int) (string, error) {
+	offset := e.Offset()
+	var qual strings.Builder
+	qual.Grow(len(scores))
+	for i, s := range scores {
+		if s < 0 || s+offset > '~' {
+			return "", fmt.Errorf("fastq: quality %d at position %d out of range for %s",
+				s, i, e)
+		}
+		qual.WriteByte(byte(s + offset))
+	}
+	return qual.String(), nil
+}
+
+// DetectEncoding guesses the quality encoding of a set of records
+// from the range of quality characters seen. Phred+64 is reported
+// only on positive evidence: characters above 'J', beyond the
+// usual Phred+33 range, with none below '@', Phred+64 quality 0.
+// Otherwise, including for empty input, binned Phred+33 data with
+// every character '@' or above, and Solexa data with characters
+// from ';' to '?', which Phred64 cannot decode, the result is
+// Phred+33. As with any such heuristic, more records give a more
+// reliable answer.
+func DetectEncoding(recs []*Record) (Encoding, error) {
+	lowest, highest := byte('~'), byte('!')
+	for _, rec := range recs {
+		for i := 0; i < len(rec.Quality); i++ {
+			c := rec.Quality[i]
+			if c < '!' || c > '~' {
+				return Phred33, fmt.Errorf("fastq: record %s: invalid quality character %q",
+					rec.ID, c)
+			}
+			lowest, highest = min(lowest, c), max(highest, c)
+		}
+	}
+	if highest > 'J' && lowest >= '@' {
+		return Phred64, nil
+	}
+	return Phred33, nil
+}
@@ -0,0 +1,193 @@
+package fastq
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// Errors describing malformed FASTQ input, wrapped in a ParseError
+var (
+	ErrNoHeader       = errors.New("expected '@' header line")
+	ErrMissingID      = errors.New("header has no sequence ID")
+	ErrNoSeparator    = errors.New("missing '+' separator line")
+	ErrIDMismatch     = errors.New("'+' line does not match header")
+	ErrLengthMismatch = errors.New("sequence and quality lengths differ")
+)
+
+// ParseError reports the location and reason of a FASTQ parsing
+// failure. Line numbers are 1-based; Offset is the byte offset of
+// the start of the offending line.
+type ParseError struct {
+	Line   int
+	Offset int64
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("fastq: line %d, byte %d: %v", e.Line, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads FASTQ records one at a time from an io.Reader.
+// Sequence and quality strings may be wrapped over several lines;
+// the quality lines of a record end once as many quality values as
+// bases have been read, so qualities starting with '@' are handled.
+type Reader struct {
+	r       *bufio.Reader
+	rec     *Record
+	err     error
+	line    int   // number of lines read
+	offset  int64 // number of bytes read
+	lineOff int64 // offset of the start of the current line
+}
+
+// NewReader returns a new Reader that reads from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// readLine returns the next line of input, without its line ending
+func (r *Reader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil && err != io.EOF {
+		return "", &ParseError{Line: r.line + 1, Offset: r.offset, Err: err}
+	}
+	if len(line) > 0 {
+		r.line++
+		r.lineOff = r.offset
+		r.offset += int64(len(line))
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// error returns a *ParseError for the current line
+func (r *Reader) error(err error) error {
+	return &ParseError{Line: r.line, Offset: r.lineOff, Err: err}
+}
+
+// Next advances the Reader to the next record, which is then
+// available through Record. It returns false when there are no
+// more records, either because the end of the input was reached or
+// an error occurred; Err distinguishes the two cases.
+func (r *Reader) Next() bool {
+	r.rec = nil
+	if r.err != nil {
+		return false
+	}
+	rec, err := r.read()
+	if err != nil {
+		r.err = err
+		return false
+	}
+	r.rec = rec
+	return true
+}
+
+func (r *Reader) read() (*Record, error) {
+	// Header, skipping blank lines between records
+	var line string
+	var err error
+	for len(strings.TrimSpace(line)) == 0 {
+		if line, err = r.readLine(); err != nil {
+			return nil, err
+		}
+	}
+	if !strings.HasPrefix(line, "@") {
+		return nil, r.error(ErrNoHeader)
+	}
+	title := strings.TrimSpace(line[1:])
+	fields := strings.Fields(title)
+	if len(fields) == 0 {
+		return nil, r.error(ErrMissingID)
+	}
+	rec := &Record{ID: fields[0]}
+	if len(fields) > 1 {
+		rec.Description = strings.Join(fields[1:], " ")
+	}
+
+	// Sequence lines, up to the '+' separator
+	var sequence strings.Builder
+	for {
+		line, err = r.readLine()
+		if err == io.EOF {
+			return nil, r.error(ErrNoSeparator)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, "+") {
+			break
+		}
+		sequence.WriteString(strings.TrimSpace(line))
+	}
+	if repeat := strings.TrimSpace(line[1:]); len(repeat) > 0 && repeat != title {
+		return nil, r.error(ErrIDMismatch)
+	}
+	rec.Sequence = sequence.String()
+
+	// Quality lines, until they cover the sequence
+	var quality strings.Builder
+	for quality.Len() < len(rec.Sequence) {
+		line, err = r.readLine()
+		if err == io.EOF {
+			return nil, r.error(ErrLengthMismatch)
+		}
+		if err != nil {
+			return nil, err
+		}
+		quality.WriteString(strings.TrimSpace(line))
+	}
+	if quality.Len() != len(rec.Sequence) {
+		return nil, r.error(ErrLengthMismatch)
+	}
+	rec.Quality = quality.String()
+	return rec, nil
+}
+
+// Record returns the most recent record read by a call to Next
+func (r *Reader) Record() *Record {
+	return r.rec
+}
+
+// Err returns the first non-EOF error encountered by the Reader
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// ReadAll reads all the remaining records from the input
+func (r *Reader) ReadAll() ([]*Record, error) {
+	var records []*Record
+	for r.Next() {
+		records = append(records, r.Record())
+	}
+	return records, r.Err()
+}
+
+// Records returns an iterator over the remaining records in the
+// input. Iteration stops after the first error, which is yielded
+// with a nil Record.
+func (r *Reader) Records() iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		for r.Next() {
+			if !yield(r.Record(), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package gff
+
+import (
+	"strings"
+)
+
+// Attribute is a single tag of the attributes column (column 9)
+// with its values, which are stored unescaped
+type Attribute struct {
+	Tag    string
+	Values []string
+}
+
+// Attributes holds the attributes of a record in file order
+type Attributes []Attribute
+
+// index returns the position of tag in a, or -1
+func (a Attributes) index(tag string) int {
+	for i := range a {
+		if a[i].Tag == tag {
+			return i
+		}
+	}
+	return -1
+}
+
+// Has reports whether the tag is present
+func (a Attributes) Has(tag string) bool {
+	return a.index(tag) >= 0
+}
+
+// Values returns the values of the tag, or nil if it is absent
+func (a Attributes) Values(tag string) []string {
+	if i := a.index(tag); i >= 0 {
+		return a[i].Values
+	}
+	return nil
+}
+
+// Get returns the values of the tag joined by commas, or the
+// empty string if it is absent
+func (a Attributes) Get(tag string) string {
+	return strings.Join(a.Values(tag), ",")
+}
+
+// Set replaces the values of the tag, adding it if it is absent
+func (a *Attributes) Set(tag string, values ...string) {
+	if i := a.index(tag); i >= 0 {
+		(*a)[i].Values = values
+		return
+	}
+	*a = append(*a, Attribute{Tag: tag, Values: values})
+}
+
+// Add appends values to the tag, adding it if it is absent
+func (a *Attributes) Add(tag string, values ...string) {
+	if i := a.index(tag); i >= 0 {
+		(*a)[i].Values = append((*a)[i].Values, values...)
+		return
+	}
+	*a = append(*a, Attribute{Tag: tag, Values: values})
+}
+
+// Delete removes the tag
+func (a *Attributes) Delete(tag string) {
+	if i := a.index(tag); i >= 0 {
+		*a = append((*a)[:i], (*a)[i+1:]...)
+	}
+}
+
+// parseAttributes parses the attributes field (column 9) of a
+// GFF3 record. Each value is unescaped separately, so escaped
+// commas (%2C) are kept within a value.
+func parseAttributes(s string) Attributes {
+	var attribs Attributes
+	if len(s) == 0 || s == "." {
+		return attribs
+	}
+	for _, field := range strings.Split(s, ";") {
+		tag, vals, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok || len(tag) == 0 {
+			continue
+		}
+		var values []string
+		for _, val := range strings.Split(vals, ",") {
+			if len(val) > 0 {
+				values = append(values, unescape(val))
+			}
+		}
+		attribs.Add(unescape(tag), values...)
+	}
+	return attribs
+}
+
+// String formats the attributes as a GFF3 column 9 string
+func (a Attributes) String() string {
+	if len(a) == 0 {
+		return "."
+	}
+	var attribstr strings.Builder
+	for i, attrib := range a {
+		if i > 0 {
+			attribstr.WriteByte(';')
+		}
+		attribstr.WriteString(escapeAttribute(attrib.Tag))
+		attribstr.WriteByte('=')
+		for j, val := range attrib.Values {
+			if j > 0 {
+				attribstr.WriteByte(',')
+			}
+			attribstr.WriteString(escapeAttribute(val))
+		}
+	}
+	return attribstr.String()
+}
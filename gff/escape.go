@@ -0,0 +1,105 @@
+package gff
+
+import (
+	"strings"
+)
+
+/*
+GFF3 uses URL-style percent escapes. Tab, newline, carriage return,
+'%' and control characters must be escaped in every column; in
+addition ';', '=', '&' and ',' have reserved meanings in column 9,
+and seqids may contain only the characters [a-zA-Z0-9.:^*$@!+_?-|].
+All other characters are written as-is.
+*/
+
+const hexDigits = "0123456789ABCDEF"
+
+// mustEscape reports whether c must be escaped in any column
+func mustEscape(c byte) bool {
+	return c < 0x20 || c == 0x7f || c == '%'
+}
+
+// isSeqIDChar reports whether c may appear unescaped in a seqid
+func isSeqIDChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		strings.IndexByte(".:^*$@!+_?-|", c) >= 0
+}
+
+// escapeFunc percent-escapes the bytes of s for which reserved
+// returns true
+func escapeFunc(s string, reserved func(byte) bool) string {
+	var n int
+	for i := 0; i < len(s); i++ {
+		if reserved(s[i]) {
+			n++
+		}
+	}
+	if n == 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 2*n)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if reserved(c) {
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0xf])
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// escapeColumn escapes a value for columns 2 to 8
+func escapeColumn(s string) string {
+	return escapeFunc(s, mustEscape)
+}
+
+// escapeSeqID escapes a value for column 1
+func escapeSeqID(s string) string {
+	return escapeFunc(s, func(c byte) bool { return !isSeqIDChar(c) })
+}
+
+// escapeAttribute escapes an attribute tag or value for column 9
+func escapeAttribute(s string) string {
+	return escapeFunc(s, func(c byte) bool {
+		return mustEscape(c) || c == ';' || c == '=' || c == '&' || c == ','
+	})
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// unescape decodes percent escapes in s. Unlike url.PathUnescape,
+// a '%' that does not begin a valid escape is kept literally.
+func unescape(s string) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			hi, ok1 := unhex(s[i+1])
+			lo, ok2 := unhex(s[i+2])
+			if ok1 && ok2 {
+				b.WriteByte(hi<<4 | lo)
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
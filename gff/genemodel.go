@@ -0,0 +1,249 @@
+package gff
+
+import (
+	"sort"
+)
+
+// FeatureClass is the role of a feature type in a gene model
+type FeatureClass int
+
+// Feature classes
+const (
+	OtherFeature FeatureClass = iota
+	GeneFeature
+	TranscriptFeature
+	ExonFeature
+	CDSFeature
+	FivePrimeUTRFeature
+	ThreePrimeUTRFeature
+	UTRFeature // a UTR not specified as 5' or 3'
+	IntronFeature
+)
+
+// featureClasses maps Sequence Ontology terms and accessions to the
+// class of feature they describe
+var featureClasses = make(map[string]FeatureClass)
+
+func init() {
+	for class, types := range map[FeatureClass][]string{
+		GeneFeature: {"gene", "SO:0000704", "pseudogene", "SO:0000336",
+			"ncRNA_gene", "SO:0001263", "protein_coding_gene",
+			"transposable_element_gene"},
+		TranscriptFeature: {"mRNA", "SO:0000234", "transcript", "SO:0000673",
+			"primary_transcript", "SO:0000185", "ncRNA", "SO:0000655",
+			"lnc_RNA", "SO:0001877", "lncRNA", "tRNA", "SO:0000253",
+			"rRNA", "SO:0000252", "snRNA", "SO:0000274", "snoRNA", "SO:0000275",
+			"miRNA", "SO:0000276", "pseudogenic_transcript", "SO:0000516",
+			"antisense_RNA", "guide_RNA", "misc_RNA", "piRNA", "RNase_MRP_RNA",
+			"RNase_P_RNA", "scRNA", "SRP_RNA", "telomerase_RNA", "vault_RNA",
+			"Y_RNA"},
+		ExonFeature: {"exon", "SO:0000147", "pseudogenic_exon", "SO:0000507",
+			"coding_exon", "SO:0000195", "noncoding_exon", "SO:0000198"},
+		CDSFeature:           {"CDS", "SO:0000316"},
+		FivePrimeUTRFeature:  {"five_prime_UTR", "SO:0000204"},
+		ThreePrimeUTRFeature: {"three_prime_UTR", "SO:0000205"},
+		UTRFeature:           {"UTR", "SO:0000203"},
+		IntronFeature:        {"intron", "SO:0000188"},
+	} {
+		for _, typ := range types {
+			featureClasses[typ] = class
+		}
+	}
+}
+
+// Classify returns the class of a feature type, given as a
+// Sequence Ontology term or accession
+func Classify(typ string) FeatureClass {
+	return featureClasses[typ]
+}
+
+// Gene is a gene record with its transcripts
+type Gene struct {
+	*Record
+	Transcripts []*Transcript
+}
+
+// Transcript is a transcript record whose Exons, Cds, Introns,
+// FivePrimeUTRs, ThreePrimeUTRs and Parts slices have been
+// populated by BuildGeneModels
+type Transcript struct {
+	*Record
+	Gene *Gene
+}
+
+// Length returns the length of the spliced transcript
+func (t *Transcript) Length() int {
+	return totalLength(t.Exons)
+}
+
+// CDSLength returns the length of the coding sequence
+func (t *Transcript) CDSLength() int {
+	return totalLength(t.Cds)
+}
+
+// ExonCount returns the number of exons
+func (t *Transcript) ExonCount() int {
+	return len(t.Exons)
+}
+
+// IsCoding reports whether the transcript has a CDS
+func (t *Transcript) IsCoding() bool {
+	return len(t.Cds) > 0
+}
+
+func totalLength(recs []*Record) int {
+	var n int
+	for _, rec := range recs {
+		n += rec.End - rec.Start + 1
+	}
+	return n
+}
+
+// childrenOfClass returns the children of rec of the given class,
+// sorted by start coordinate
+func childrenOfClass(rec *Record, class FeatureClass) []*Record {
+	var parts []*Record
+	for _, child := range rec.Children {
+		if Classify(child.Type) == class {
+			parts = append(parts, child)
+		}
+	}
+	sortByStart(parts)
+	return parts
+}
+
+func sortByStart(recs []*Record) {
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].Start < recs[j].Start
+	})
+}
+
+// newPart returns a new feature of transcript t
+func newPart(t *Record, typ string, start, end int) *Record {
+	return &Record{SeqID: t.SeqID, Source: t.Source, Type: typ,
+		Start: start, End: end, Strand: t.Strand, Phase: ".",
+		Parents: []string{t.ID}}
+}
+
+// BuildGeneModels assembles records, with Children linked, into
+// genes and transcripts. Features are classified by their Sequence
+// Ontology types. For each transcript the Exons, Cds, Introns,
+// FivePrimeUTRs, ThreePrimeUTRs and Parts fields are populated:
+// exons missing from the file are inferred from CDS and UTRs (or
+// from the transcript itself), introns from the gaps between
+// exons, and UTRs from the parts of exons outside the CDS.
+// Inferred features are new records and are not added to recs.
+// Transcripts without a gene are given one, synthesized to span
+// the transcript, and genes with exons but no transcripts are
+// treated as their own transcript.
+func BuildGeneModels(recs []*Record) []*Gene {
+	var genes []*Gene
+	var seen = make(map[*Record]bool)
+	var hasGene = make(map[*Record]bool)
+	for _, rec := range recs {
+		if Classify(rec.Type) == GeneFeature {
+			for _, child := range rec.Children {
+				hasGene[child] = true
+			}
+		}
+	}
+	for _, rec := range recs {
+		switch Classify(rec.Type) {
+		case GeneFeature:
+			if seen[rec] {
+				continue
+			}
+			seen[rec] = true
+			gene := &Gene{Record: rec}
+			for _, child := range rec.Children {
+				if Classify(child.Type) == TranscriptFeature {
+					gene.addTranscript(child)
+					seen[child] = true
+				}
+			}
+			if len(gene.Transcripts) == 0 && (len(childrenOfClass(rec, ExonFeature)) > 0 ||
+				len(childrenOfClass(rec, CDSFeature)) > 0) {
+				gene.addTranscript(rec)
+			}
+			genes = append(genes, gene)
+		case TranscriptFeature:
+			if seen[rec] || hasGene[rec] {
+				continue
+			}
+			seen[rec] = true
+			generec := span([]*Record{rec}, "gene")
+			generec.ID, generec.IsGene = rec.ID, true
+			generec.Children = []*Record{rec}
+			gene := &Gene{Record: generec}
+			gene.addTranscript(rec)
+			genes = append(genes, gene)
+		}
+	}
+	return genes
+}
+
+func (g *Gene) addTranscript(rec *Record) {
+	t := &Transcript{Record: rec, Gene: g}
+	t.build()
+	g.Transcripts = append(g.Transcripts, t)
+}
+
+// build populates the part slices of a transcript
+func (t *Transcript) build() {
+	t.Parts = append([]*Record(nil), t.Children...)
+	sortByStart(t.Parts)
+	t.Cds = childrenOfClass(t.Record, CDSFeature)
+	five := childrenOfClass(t.Record, FivePrimeUTRFeature)
+	three := childrenOfClass(t.Record, ThreePrimeUTRFeature)
+	utrs := childrenOfClass(t.Record, UTRFeature)
+
+	// Exons, inferred from the other parts if necessary
+	t.Exons = childrenOfClass(t.Record, ExonFeature)
+	if len(t.Exons) == 0 {
+		parts := append(append(append(append([]*Record(nil), t.Cds...), five...), three...), utrs...)
+		sortByStart(parts)
+		for _, part := range parts {
+			if n := len(t.Exons); n > 0 && part.Start <= t.Exons[n-1].End+1 {
+				t.Exons[n-1].End = max(t.Exons[n-1].End, part.End)
+				continue
+			}
+			t.Exons = append(t.Exons, newPart(t.Record, "exon", part.Start, part.End))
+		}
+		if len(t.Exons) == 0 {
+			t.Exons = []*Record{newPart(t.Record, "exon", t.Start, t.End)}
+		}
+	}
+
+	// Introns, from the gaps between exons
+	t.Introns = nil
+	for i := 1; i < len(t.Exons); i++ {
+		if start, end := t.Exons[i-1].End+1, t.Exons[i].Start-1; start <= end {
+			t.Introns = append(t.Introns, newPart(t.Record, "intron", start, end))
+		}
+	}
+
+	// UTRs, from the parts of exons outside the CDS
+	if len(five) == 0 && len(three) == 0 && len(t.Cds) > 0 {
+		cdsStart, cdsEnd := t.Cds[0].Start, t.Cds[len(t.Cds)-1].End
+		var left, right []*Record
+		for _, exon := range t.Exons {
+			if exon.Start < cdsStart {
+				left = append(left, newPart(t.Record, "", exon.Start, min(exon.End, cdsStart-1)))
+			}
+			if exon.End > cdsEnd {
+				right = append(right, newPart(t.Record, "", max(exon.Start, cdsEnd+1), exon.End))
+			}
+		}
+		five, three = left, right
+		if t.Strand == "-" {
+			five, three = right, left
+		}
+		for _, utr := range five {
+			utr.Type = "five_prime_UTR"
+		}
+		for _, utr := range three {
+			utr.Type = "three_prime_UTR"
+		}
+	}
+	t.FivePrimeUTRs, t.ThreePrimeUTRs = five, three
+}
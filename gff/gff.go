@@ -1,15 +1,13 @@
 package gff
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"io"
-	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/pmagwene/biofiles/fasta"
+	"github.com/pmagwene/biofiles/interval"
 )
 
 /*
@@ -126,13 +124,15 @@ type Record struct {
 	Score      float64
 	Strand     string
 	Phase      string
-	Attributes map[string]string
+	Attributes Attributes
 
-	// the ten standard reserved attributes
+	// the ten standard reserved attributes; multi-valued
+	// attributes other than Parent hold their values joined by
+	// commas, see Attributes.Values for the individual values
 	ID           string
 	Name         string
 	Alias        string
-	Parent       string
+	Parents      []string
 	Target       string
 	Gap          string
 	DerivesFrom  string
@@ -145,10 +145,14 @@ type Record struct {
 	IsGene      bool
 	ScoreExists bool
 	Children    []*Record
-	Cds         []*Record
-	Parts       []*Record
-	Exons       []*Record
-	Introns     []*Record
+
+	// parts of transcripts, populated by BuildGeneModels
+	Cds            []*Record
+	Parts          []*Record
+	Exons          []*Record
+	Introns        []*Record
+	FivePrimeUTRs  []*Record
+	ThreePrimeUTRs []*Record
 }
 
 func (r *Record) String() string {
@@ -156,6 +160,22 @@ func (r *Record) String() string {
 		r.ID, r.Type, r.SeqID, r.Start, r.End, r.Strand)
 }
 
+// Interval returns the position of the record, in 1-based closed
+// coordinates
+func (r *Record) Interval() interval.Interval {
+	iv := interval.Interval{SeqID: r.SeqID, Start: r.Start, End: r.End}
+	if r.Strand == "+" || r.Strand == "-" {
+		iv.Strand = r.Strand[0]
+	}
+	return iv
+}
+
+// NewIndex builds an interval index over a slice of records, for
+// fast overlap and nearest feature queries
+func NewIndex(recs []*Record) *interval.Tree[*Record] {
+	return interval.NewTree(recs, (*Record).Interval)
+}
+
 // ParseRecord turns a string into a single GFF record
 func ParseRecord(s string) (*Record, error) {
 
@@ -165,17 +185,19 @@ func ParseRecord(s string) (*Record, error) {
 	if len(parts) != 9 {
 		return &r, fmt.Errorf("Invalid GFF record string")
 	}
-	r.SeqID = parts[0]
-	r.Source = parts[1]
-	r.Type = parts[2]
+	r.SeqID = unescape(parts[0])
+	r.Source = unescape(parts[1])
+	r.Type = unescape(parts[2])
 	start, err := strconv.ParseInt(parts[3], 10, 0)
-	if err == nil {
-		r.Start = int(start)
+	if err != nil {
+		return &r, fmt.Errorf("Invalid GFF start %q", parts[3])
 	}
+	r.Start = int(start)
 	end, err := strconv.ParseInt(parts[4], 10, 0)
-	if err == nil {
-		r.End = int(end)
+	if err != nil {
+		return &r, fmt.Errorf("Invalid GFF end %q", parts[4])
 	}
+	r.End = int(end)
 	score, err := strconv.ParseFloat(parts[5], 64)
 	if err == nil {
 		r.Score = score
@@ -184,188 +206,129 @@ func ParseRecord(s string) (*Record, error) {
 	r.Strand = parts[6]
 	r.Phase = parts[7]
 
-	if r.Type == "gene" {
-		r.IsGene = true
-	}
+	r.IsGene = Classify(r.Type) == GeneFeature
 
 	// parse attributes field, setting standard attribute
 	r.Attributes = parseAttributes(parts[8])
-	r.ID = r.Attributes["ID"]
-	r.Name = r.Attributes["Name"]
-	r.Parent = r.Attributes["Parent"]
-	r.Target = r.Attributes["Target"]
-	r.Gap = r.Attributes["Gap"]
-	r.DerivesFrom = r.Attributes["Derives_from"]
-	r.Note = r.Attributes["Note"]
-	r.Dbxref = r.Attributes["Dbxref"]
-	r.OntologyTerm = r.Attributes["Ontology_term"]
+	r.ID = r.Attributes.Get("ID")
+	r.Name = r.Attributes.Get("Name")
+	r.Alias = r.Attributes.Get("Alias")
+	r.Parents = r.Attributes.Values("Parent")
+	r.Target = r.Attributes.Get("Target")
+	r.Gap = r.Attributes.Get("Gap")
+	r.DerivesFrom = r.Attributes.Get("Derives_from")
+	r.Note = r.Attributes.Get("Note")
+	r.Dbxref = r.Attributes.Get("Dbxref")
+	r.OntologyTerm = r.Attributes.Get("Ontology_term")
 
 	return &r, nil
 
 }
 
-func parseAttributes(s string) map[string]string {
-	// Parse the attributes field (column 9) of a GFF3 record.
-	attribDict := make(map[string]string)
-	if len(s) == 0 {
-		return attribDict
-	}
-	if s == "." {
-		return attribDict
-	}
-	fields := strings.Split(s, ";")
-	for _, val := range fields {
-		attribval := strings.SplitN(val, "=", 2)
-		if len(attribval) < 2 {
-			continue
-		}
-		unescaped, _ := url.PathUnescape(attribval[1])
-		attribDict[attribval[0]] = unescaped
-	}
-	return attribDict
+// ParentRef is a Parent attribute value of a record
+type ParentRef struct {
+	Record *Record
+	Parent string
 }
 
-func buildAttributeStr(rec *Record) string {
-	var fmtstr string
-	var attribstr strings.Builder
-	stdattribs := [...]string{"ID", "Name", "Parent", "Target",
-		"Gap", "Derives_from", "Note", "Dbxref", "Ontology_term"}
-
-	// Deal with standard attributes in given order
-	for _, attrib := range stdattribs {
-		val := rec.Attributes[attrib]
-		if len(val) < 1 {
-			continue
-		}
-		if attribstr.Len() > 0 {
-			fmtstr = ";%s=%s"
-		} else {
-			fmtstr = "%s=%s"
-		}
-		attribstr.WriteString(
-			fmt.Sprintf(fmtstr, attrib, url.PathEscape(val)))
+// MissingParentError reports Parent attributes referring to IDs
+// that are not present in the records
+type MissingParentError struct {
+	Refs []ParentRef
+}
 
-	}
-	// Deal with other attributes
-	for attrib, val := range rec.Attributes {
-		switch attrib {
-		case "ID", "Name", "Parent", "Target", "Gap",
-			"Derives_from", "Note", "Dbxref", "Ontology_term":
-			continue
-		default:
-			if attribstr.Len() > 0 {
-				fmtstr = ";%s=%s"
-			} else {
-				fmtstr = "%s=%s"
-			}
-			attribstr.WriteString(
-				fmt.Sprintf(fmtstr, attrib, url.PathEscape(val)))
-		}
-	}
-	return attribstr.String()
+func (e *MissingParentError) Error() string {
+	ref := e.Refs[0]
+	return fmt.Sprintf("gff: %d undefined Parent references, first %q from %s %s:%d..%d",
+		len(e.Refs), ref.Parent, ref.Record.Type, ref.Record.SeqID,
+		ref.Record.Start, ref.Record.End)
 }
 
-// Populate the Children field of a slice of Record structs
-func populateChildren(recs []*Record) {
+// LinkChildren populates the Children field of a slice of Record
+// structs, linking each record to every one of its Parents. If an
+// ID occurs on several lines, as for multi-line features, children
+// are linked to its first record. Parent IDs that are not found
+// are reported in a *MissingParentError; all other links are
+// still made.
+func LinkChildren(recs []*Record) error {
 	var tbl = make(map[string]*Record)
-	for i, rec := range recs {
-		tbl[rec.ID] = recs[i]
+	for _, rec := range recs {
+		rec.Children = nil
+		if _, ok := tbl[rec.ID]; !ok && rec.ID != "" {
+			tbl[rec.ID] = rec
+		}
 	}
-	for i, rec := range recs {
-		if rec.Parent != "" {
-			therec := recs[i]
-			parent := tbl[therec.Parent]
-			parent.Children = append(parent.Children, therec)
+	var missing []ParentRef
+	for _, rec := range recs {
+		for _, id := range rec.Parents {
+			parent, ok := tbl[id]
+			if !ok {
+				missing = append(missing, ParentRef{rec, id})
+				continue
+			}
+			parent.Children = append(parent.Children, rec)
 		}
 	}
+	if len(missing) > 0 {
+		return &MissingParentError{missing}
+	}
+	return nil
 }
 
 // ParseAll parses GFF records, returning a slice of
-// *Record and a slice(possibly empty) with associated fasta.Records
+// *Record and a slice(possibly empty) with associated fasta.Records.
+// Unparseable lines are skipped. Children are linked as by
+// LinkChildren, ignoring missing parents.
 func ParseAll(r io.Reader) ([]*Record, []*fasta.Record) {
-	var records []*Record
-	var isFasta bool
-	var fastastr strings.Builder
-
-	input := bufio.NewScanner(r)
-	for input.Scan() {
-		line := strings.TrimSpace(input.Text())
-		if len(line) == 0 {
-			continue
-		}
-		// Comment lines
-		if strings.HasPrefix(line, "#") {
-			// Marks beginning of FASTA section
-			if strings.HasPrefix(line, "##FASTA") {
-				isFasta = true
-			}
-			continue
-		}
-		// Process FASTA
-		if isFasta {
-			fastastr.WriteString(line)
-			fastastr.WriteByte('\n')
-			continue
-		}
-		// If not FASTA, process Record
-		rec, err := ParseRecord(line)
-		if err == nil {
-			records = append(records, rec)
-		}
-	}
-	populateChildren(records)
-
-	var fastarecs []*fasta.Record
-	if fastastr.Len() > 0 {
-		fastarecs = fasta.ParseAll(strings.NewReader(fastastr.String()))
-	}
-	return records, fastarecs
+	reader := NewReader(r)
+	reader.SkipInvalid = true
+	f, _ := readFile(reader)
+	return f.Records, f.Fasta
 }
 
-// WriteRecord writes a single GFF record to the given Writer
-func WriteRecord(r *Record, w io.Writer) {
-	var b bytes.Buffer
-	scorestr := "."
-	if r.ScoreExists {
-		scorestr = fmt.Sprintf("%f", r.Score)
-	}
-	attribstr := buildAttributeStr(r)
-	fmtstr := "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\n"
-	recstr := fmt.Sprintf(fmtstr,
-		r.SeqID, r.Source, r.Type, r.Start, r.End,
-		scorestr, r.Strand, r.Phase, attribstr)
-	b.WriteString(recstr)
+// ParseFile parses a GFF3 file, returning its directives, records
+// and any associated FASTA sequences. Children are linked as by
+// LinkChildren, ignoring missing parents. Parsing stops at the
+// first malformed line, which is reported as a *ParseError.
+func ParseFile(r io.Reader) (*File, error) {
+	return readFile(NewReader(r))
 }
 
-// WriteAll writes string representations of GFF records, and
-// optional associated Fasta records, to given Writer interface
-func WriteAll(recs []*Record, w io.Writer) {
-	for _, rec := range recs {
-		WriteRecord(rec, w)
+func readFile(reader *Reader) (*File, error) {
+	var f = &File{Header: reader.Header()}
+	addBarriers := func() {
+		for range reader.barriers {
+			f.Barriers = append(f.Barriers, len(f.Records))
+		}
 	}
-}
+	for reader.Next() {
+		addBarriers()
+		f.Records = append(f.Records, reader.Record())
+	}
+	if err := reader.Err(); err != nil {
+		return f, err
+	}
+	addBarriers()
+	LinkChildren(f.Records)
 
-// WriteFastaSection appends an optional Fasta section to a GFF file.
-// WriteFastaSection should be called after gff.Write or gff.WriteAll
-func WriteFastaSection(fastarecs []*fasta.Record, w io.Writer) {
-	w.Write([]byte("##FASTA\n"))
-	fasta.WriteAll(fastarecs, w)
+	if fr := reader.Fasta(); fr != nil {
+		var err error
+		f.Fasta, err = fr.ReadAll()
+		return f, err
+	}
+	return f, nil
 }
 
 // ToFastaRecord generates a fasta.Record that corresponds to the given
 // GFF Record. lwindow and rwindow parameters facilitate specification of a
-// sequence window around the feature.
+// sequence window around the feature; the window is truncated at the
+// ends of the sequence.
 func (r Record) ToFastaRecord(fastadict map[string]*fasta.Record,
 	lwindow int, rwindow int) *fasta.Record {
 	target := fastadict[r.SeqID]
-	wstart := r.Start - 1 - lwindow
-	wend := r.End - 1 + rwindow + 1 // additional +1 to include rightmost coord
-	if wstart < 0 {
-		wstart = 0
-	}
-	if wend > len(target.Sequence)-1 {
-		wend = len(target.Sequence) - 1
-	}
+	window := interval.Interval{SeqID: r.SeqID, Start: r.Start, End: r.End}.Slop(lwindow, rwindow)
+	window.End = min(window.End, len(target.Sequence))
+	z := window.In(interval.ZeroBased)
 	idstr := r.ID
 	if len(idstr) < 1 {
 		idstr = fmt.Sprintf("%s_%s_%s_%d_%d",
@@ -374,6 +337,6 @@ func (r Record) ToFastaRecord(fastadict map[string]*fasta.Record,
 	return &fasta.Record{
 		ID: idstr,
 		Description: fmt.Sprintf("%s:%d..%d",
-			r.SeqID, wstart+1, wend),
-		Sequence: target.Sequence[wstart:wend]}
+			r.SeqID, window.Start, window.End),
+		Sequence: target.Sequence[z.Start:z.End]}
 }
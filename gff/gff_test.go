@@ -2,12 +2,15 @@ package gff
 
 import (
 	"fmt"
+	"os"
 	"strings"
+
+	"github.com/pmagwene/biofiles/fasta"
 )
 
-func ExampleParseGFFRecord() {
+func ExampleParseRecord() {
 	var oneGFF = "chrI	SGD	telomere	1	801	.	-	.	ID=TEL01L;Name=TEL01L"
-	rec, _ := ParseGFFRecord(oneGFF)
+	rec, _ := ParseRecord(oneGFF)
 	fmt.Println(rec.Start)
 	fmt.Println(rec.End)
 	fmt.Println(rec.ID)
@@ -17,7 +20,7 @@ func ExampleParseGFFRecord() {
 	// TEL01L
 }
 
-func ExampleParseGFF() {
+func ExampleParseAll() {
 	var manyGFF = `
 chrI	SGD	chromosome	1	230218	.	.	.	ID=chrI;dbxref=NCBI:NC_001133;Name=chrI
 chrI	SGD	telomere	1	801	.	-	.	ID=TEL01L;Name=TEL01L
@@ -25,14 +28,286 @@ chrI	SGD	gene	335	649	.	+	.	ID=YAL069W;Name=YAL069W;
 chrI	SGD	CDS	335	649	.	+	0	Parent=YAL069W_mRNA;Name=YAL069W_CDS;
 chrI	SGD	mRNA	335	649	.	+	.	ID=YAL069W_mRNA;Name=YAL069W_mRNA;Parent=YAL069W
 `
-	recs, _ := ParseGFF(strings.NewReader(manyGFF))
+	recs, _ := ParseAll(strings.NewReader(manyGFF))
 	fmt.Println(len(recs))
 	fmt.Println(recs[0].Type)
 	fmt.Println(recs[len(recs)-1].Type)
-	fmt.Println(recs[len(recs)-1].Parent)
+	fmt.Println(recs[len(recs)-1].Parents)
 	// Output:
 	// 5
 	// chromosome
 	// mRNA
-	// YAL069W
+	// [YAL069W]
+}
+
+func ExampleSplicedTranscripts() {
+	var gene = `
+chr1	.	gene	3	21	.	-	.	ID=g1
+chr1	.	mRNA	3	21	.	-	.	ID=t1;Parent=g1
+chr1	.	exon	3	9	.	-	.	Parent=t1
+chr1	.	exon	13	21	.	-	.	Parent=t1
+chr1	.	CDS	5	9	.	-	2	Parent=t1
+chr1	.	CDS	13	19	.	-	0	Parent=t1
+`
+	recs, _ := ParseAll(strings.NewReader(gene))
+	genome := map[string]*fasta.Record{
+		"chr1": {ID: "chr1", Sequence: "GGCCTTACATTTGTTTCATGGAA"},
+	}
+	tx, _ := SplicedTranscripts(recs[0], genome)
+	cds, _ := SplicedCDS(recs[0], genome)
+	prot, _ := Proteins(recs[0], genome, 1)
+	fmt.Println(tx[0].ID, tx[0].Description, tx[0].Sequence)
+	fmt.Println(cds[0].Sequence)
+	fmt.Println(prot[0].Sequence)
+	// Output:
+	// t1 chr1:3..9,13..21 CCATGAAACTGTAAGG
+	// ATGAAACTGTAA
+	// MKL
+}
+
+func ExampleSplicedTranscripts_sequenceOntology() {
+	var gene = `
+chr1	.	SO:0000704	3	21	.	-	.	ID=g1
+chr1	.	SO:0000234	3	21	.	-	.	ID=t1;Parent=g1
+chr1	.	noncoding_exon	3	4	.	-	.	Parent=t1
+chr1	.	coding_exon	5	9	.	-	.	Parent=t1
+chr1	.	coding_exon	13	21	.	-	.	Parent=t1
+chr1	.	SO:0000316	5	9	.	-	2	Parent=t1
+chr1	.	SO:0000316	13	19	.	-	0	Parent=t1
+`
+	recs, _ := ParseAll(strings.NewReader(gene))
+	genome := map[string]*fasta.Record{
+		"chr1": {ID: "chr1", Sequence: "GGCCTTACATTTGTTTCATGGAA"},
+	}
+	tx, _ := SplicedTranscripts(recs[0], genome)
+	prot, _ := Proteins(recs[0], genome, 1)
+	fmt.Println(tx[0].ID, tx[0].Description, tx[0].Sequence)
+	fmt.Println(prot[0].Sequence)
+	// Output:
+	// t1 chr1:3..4,5..9,13..21 CCATGAAACTGTAAGG
+	// MKL
+}
+
+func ExampleWriter() {
+	var oneGFF = "chr I	SGD	gene	335	649	12.50	+	.	Name=YAL069W;ID=YAL069W;note=a%3Bb c;Dbxref=SGD:1,NCBI:2"
+	rec, _ := ParseRecord(oneGFF)
+	w := NewWriter(os.Stdout)
+	w.WriteDirective("sequence-region", "chrI 1 230218")
+	w.Write(rec)
+	fmt.Println(w.WriteHeader(&Header{Version: "3"}))
+	w.Flush()
+	// Output:
+	// gff: header written after other output
+	// ##gff-version 3
+	// ##sequence-region chrI 1 230218
+	// chr%20I	SGD	gene	335	649	12.5	+	.	Name=YAL069W;ID=YAL069W;note=a%3Bb c;Dbxref=SGD:1,NCBI:2
+}
+
+func ExampleLinkChildren() {
+	var manyGFF = `
+chrI	SGD	gene	335	649	.	+	.	ID=g1
+chrI	SGD	mRNA	335	649	.	+	.	ID=tx1;Parent=g1
+chrI	SGD	mRNA	335	649	.	+	.	ID=tx2;Parent=g1
+chrI	SGD	exon	335	649	.	+	.	Parent=tx1,tx2,tx3;Note=shared%2C first exon
+`
+	recs, _ := ParseAll(strings.NewReader(manyGFF))
+	exon := recs[3]
+	fmt.Println(exon.Parents, exon.Attributes.Values("Note"))
+	fmt.Println(len(recs[1].Children), len(recs[2].Children))
+	fmt.Println(LinkChildren(recs))
+	// Output:
+	// [tx1 tx2 tx3] [shared, first exon]
+	// 1 1
+	// gff: 1 undefined Parent references, first "tx3" from exon chrI:335..649
+}
+
+func ExampleParseFile() {
+	var gffFile = `##gff-version 3.1.26
+##sequence-region ctg123 1 1497228
+##species https://www.ncbi.nlm.nih.gov/Taxonomy/Browser/wwwtax.cgi?id=6239
+ctg123	.	gene	1000	9000	.	+	.	ID=gene00001
+###
+ctg123	.	gene	11000	15000	.	-	.	ID=gene00002
+###
+###
+##FASTA
+>ctg123
+ACGT
+`
+	f, _ := ParseFile(strings.NewReader(gffFile))
+	fmt.Println(f.Header.SequenceRegion("ctg123"))
+	fmt.Println(len(f.Records), f.Barriers, len(f.Fasta))
+	WriteFile(f, os.Stdout)
+	// Output:
+	// {ctg123 1 1497228} true
+	// 2 [1 2 2] 1
+	// ##gff-version 3.1.26
+	// ##sequence-region ctg123 1 1497228
+	// ##species https://www.ncbi.nlm.nih.gov/Taxonomy/Browser/wwwtax.cgi?id=6239
+	// ctg123	.	gene	1000	9000	.	+	.	ID=gene00001
+	// ###
+	// ctg123	.	gene	11000	15000	.	-	.	ID=gene00002
+	// ###
+	// ###
+	// ##FASTA
+	// >ctg123
+	// ACGT
+}
+
+func ExampleGTFToGFF3() {
+	var gtf = `chr1	HAVANA	exon	11869	12227	.	+	.	gene_id "G1"; transcript_id "T1"; gene_name "DDX11L1"; exon_number 1;
+chr1	HAVANA	exon	12613	12721	.	+	.	gene_id "G1"; transcript_id "T1"; exon_number 2;
+chr1	HAVANA	CDS	12010	12057	.	+	0	gene_id "G1"; transcript_id "T1"; tag "basic"; tag "CCDS";
+`
+	recs, _ := ParseGTF(strings.NewReader(gtf))
+	gff3 := GTFToGFF3(recs)
+	WriteAll(gff3, os.Stdout)
+	WriteGTF(GFF3ToGTF(gff3)[:3], os.Stdout)
+	// Output:
+	// ##gff-version 3
+	// chr1	HAVANA	gene	11869	12721	.	+	.	ID=G1
+	// chr1	HAVANA	mRNA	11869	12721	.	+	.	ID=T1;Parent=G1
+	// chr1	HAVANA	exon	11869	12227	.	+	.	Parent=T1;gene_name=DDX11L1;exon_number=1
+	// chr1	HAVANA	exon	12613	12721	.	+	.	Parent=T1;exon_number=2
+	// chr1	HAVANA	CDS	12010	12057	.	+	0	Parent=T1;tag=basic,CCDS
+	// chr1	HAVANA	gene	11869	12721	.	+	.	gene_id "G1";
+	// chr1	HAVANA	transcript	11869	12721	.	+	.	gene_id "G1"; transcript_id "T1";
+	// chr1	HAVANA	exon	11869	12227	.	+	.	gene_id "G1"; transcript_id "T1"; gene_name "DDX11L1"; exon_number "1";
+}
+
+func ExampleGFF3ToGTF() {
+	// The mRNA precedes its gene
+	var gffFile = `##gff-version 3
+chr1	.	mRNA	1000	2000	.	-	.	ID=tx1;Parent=gene1
+chr1	.	gene	1000	2000	.	-	.	ID=gene1;Name=abc1
+chr1	.	exon	1000	2000	.	-	.	Parent=tx1
+`
+	recs, _ := ParseAll(strings.NewReader(gffFile))
+	WriteGTF(GFF3ToGTF(recs), os.Stdout)
+	// Output:
+	// chr1	.	gene	1000	2000	.	-	.	gene_id "gene1"; Name "abc1";
+	// chr1	.	transcript	1000	2000	.	-	.	gene_id "gene1"; transcript_id "tx1";
+	// chr1	.	exon	1000	2000	.	-	.	gene_id "gene1"; transcript_id "tx1";
+}
+
+func ExampleReader() {
+	var gffFile = `##gff-version 3
+chr1	.	gene	100	900	.	+	.	ID=g1
+chr1	.	gene	2000	3x00	.	-	.	ID=g2
+chr1	.	gene	4000	5000	.	+	.	ID=g3
+`
+	reader := NewReader(strings.NewReader(gffFile))
+	for {
+		for reader.Next() {
+			fmt.Println(reader.Line(), reader.Record().ID)
+		}
+		if reader.Err() == nil {
+			break
+		}
+		// Report the malformed line and resume after it
+		fmt.Println(reader.Err())
+	}
+	// Output:
+	// 2 g1
+	// gff: line 3: Invalid GFF end "3x00"
+	// 4 g3
+}
+
+func ExampleReader_NextGroup() {
+	var gffFile = `##gff-version 3
+chr1	.	mRNA	100	900	.	+	.	ID=tx1
+chr1	.	exon	100	300	.	+	.	Parent=tx1
+chr1	.	exon	600	900	.	+	.	Parent=tx1
+###
+chr1	.	gene	2000	3000	.	-	.	ID=g2
+chr2	.	gene	50	80	.	+	.	ID=g3
+chr2	.	gene	90	110	.	+	.	ID=g4
+chr2	.	gene	90	1x0	.	+	.	ID=g5
+`
+	reader := NewReader(strings.NewReader(gffFile))
+	for group := range reader.Groups() {
+		if group != nil {
+			fmt.Println(len(group), group[0].ID, len(group[0].Children))
+		}
+	}
+	fmt.Println(reader.Err())
+	// Output:
+	// 3 tx1 2
+	// 1 g2 0
+	// 2 g3 0
+	// gff: line 9: Invalid GFF end "1x0"
+}
+
+func ExampleBuildGeneModels() {
+	var gene = `
+chr1	.	gene	1000	9000	.	-	.	ID=g1
+chr1	.	mRNA	1000	9000	.	-	.	ID=t1;Parent=g1
+chr1	.	exon	1000	1500	.	-	.	Parent=t1
+chr1	.	exon	3000	3902	.	-	.	Parent=t1
+chr1	.	exon	7000	9000	.	-	.	Parent=t1
+chr1	.	CDS	1201	1500	.	-	2	Parent=t1
+chr1	.	CDS	3000	3902	.	-	0	Parent=t1
+chr1	.	CDS	7000	7600	.	-	0	Parent=t1
+chr1	.	ncRNA	1200	2000	.	+	.	ID=nc1
+`
+	recs, _ := ParseAll(strings.NewReader(gene))
+	for _, g := range BuildGeneModels(recs) {
+		for _, t := range g.Transcripts {
+			fmt.Println(g.ID, t.ID, t.ExonCount(), t.Length(), t.CDSLength())
+			fmt.Println(t.Introns, t.FivePrimeUTRs, t.ThreePrimeUTRs)
+		}
+	}
+	// Output:
+	// g1 t1 3 3405 1804
+	// [(, intron, chr1, 1501, 2999, -) (, intron, chr1, 3903, 6999, -)] [(, five_prime_UTR, chr1, 7601, 9000, -)] [(, three_prime_UTR, chr1, 1000, 1200, -)]
+	// nc1 nc1 1 801 0
+	// [] [] []
+}
+
+func ExampleValidate() {
+	var gffFile = `##gff-version 3
+##sequence-region chr1 1 5000
+chr1	.	gene	1000	9000	.	+	.	ID=g1
+chr1	.	mRNA	900	4000	.	+	.	ID=t1;Parent=g1,g2
+chr1	.	CDS	1200	1100	.	*	.	Parent=t1;Note=a=b
+chr 1	.	gene	1	10	.	+	.	ID=g1
+chr1	.	gene	1	x	.	+	.	ID=g3
+`
+	diags, _ := Validate(strings.NewReader(gffFile))
+	for _, d := range diags {
+		fmt.Println(d)
+	}
+	// Output:
+	// line 3: outside sequence-region: 1000..9000 outside chr1 1..5000
+	// line 4: undefined Parent: Parent g2 is not defined
+	// line 4: outside parent: mRNA chr1:900..4000 extends beyond parent g1 chr1:1000..9000
+	// line 5: invalid coordinates: start 1200, end 1100
+	// line 5: invalid strand: strand "*"
+	// line 5: invalid phase: CDS has no phase
+	// line 5: unescaped character: '=' in value of Note
+	// line 6: unescaped character: ' ' in seqid
+	// line 6: duplicate ID: ID g1 also used on line 3
+	// line 7: malformed line: Invalid GFF end "x"
+}
+
+func ExampleRepairPhases() {
+	var gffFile = `##gff-version 3
+chr1	.	mRNA	100	400	.	-	.	ID=t1
+chr1	.	CDS	100	150	.	-	0	Parent=t1
+chr1	.	CDS	200	260	.	-	0	Parent=t1
+chr1	.	CDS	300	400	.	-	0	Parent=t1
+`
+	recs, _ := ParseAll(strings.NewReader(gffFile))
+	mismatches, _ := RepairPhases(recs[0])
+	for _, m := range mismatches {
+		fmt.Println(m)
+	}
+	for _, cds := range recs[0].Children {
+		fmt.Println(cds.Start, cds.End, cds.Phase)
+	}
+	// Output:
+	// CDS chr1:200..260 phase 0, expected 1
+	// 100 150 0
+	// 200 260 1
+	// 300 400 0
 }
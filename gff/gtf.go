@@ -0,0 +1,406 @@
+package gff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+GTF (GFF2.5) shares the first eight columns of GFF3, but column 9
+holds space-separated, semicolon-terminated `key "value";` pairs,
+and the feature hierarchy is given by gene_id and transcript_id
+attributes rather than by ID and Parent:
+
+chr1  HAVANA  gene        11869  14409  .  +  .  gene_id "ENSG00000223972"; gene_name "DDX11L1";
+chr1  HAVANA  transcript  11869  14409  .  +  .  gene_id "ENSG00000223972"; transcript_id "ENST00000456328";
+chr1  HAVANA  exon        11869  12227  .  +  .  gene_id "ENSG00000223972"; transcript_id "ENST00000456328"; exon_number 1;
+
+When parsing GTF, records are given an ID and Parents derived from
+these attributes, so that LinkChildren and the other functions of
+this package work on them, but Attributes holds the GTF attributes
+unchanged.
+*/
+
+// parseGTFAttributes parses the attributes field of a GTF record.
+// Repeated keys, such as tag, become multiple values.
+func parseGTFAttributes(s string) (Attributes, error) {
+	var attribs Attributes
+	var field strings.Builder
+	var inQuotes bool
+	var fields []string
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ';' && !inQuotes:
+			fields = append(fields, field.String())
+			field.Reset()
+			continue
+		}
+		field.WriteByte(c)
+	}
+	if inQuotes {
+		return attribs, fmt.Errorf("gff: unterminated quote in GTF attributes")
+	}
+	fields = append(fields, field.String())
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if len(f) == 0 {
+			continue
+		}
+		key, val, _ := strings.Cut(f, " ")
+		val = strings.TrimSpace(val)
+		if uq, err := strconv.Unquote(val); err == nil {
+			val = uq
+		} else {
+			val = strings.Trim(val, `"`)
+		}
+		attribs.Add(key, val)
+	}
+	return attribs, nil
+}
+
+// ParseGTFRecord turns a line of a GTF file into a single Record.
+// Gene records take their ID from gene_id, transcript records take
+// their ID from transcript_id and their parent from gene_id, and
+// all other features have the transcript (or, lacking one, the
+// gene) as their parent.
+func ParseGTFRecord(s string) (*Record, error) {
+	var r Record
+
+	parts := strings.Split(strings.TrimRight(s, "\r\n"), "\t")
+	if len(parts) != 9 {
+		return &r, fmt.Errorf("Invalid GTF record string")
+	}
+	r.SeqID = parts[0]
+	r.Source = parts[1]
+	r.Type = parts[2]
+	start, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return &r, fmt.Errorf("Invalid GTF start %q", parts[3])
+	}
+	end, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return &r, fmt.Errorf("Invalid GTF end %q", parts[4])
+	}
+	r.Start, r.End = start, end
+	score, err := strconv.ParseFloat(parts[5], 64)
+	if err == nil {
+		r.Score = score
+		r.ScoreExists = true
+	}
+	r.Strand = parts[6]
+	r.Phase = parts[7]
+	r.IsGene = Classify(r.Type) == GeneFeature
+
+	r.Attributes, err = parseGTFAttributes(parts[8])
+	if err != nil {
+		return &r, err
+	}
+	geneID := r.Attributes.Get("gene_id")
+	transcriptID := r.Attributes.Get("transcript_id")
+	switch {
+	case r.Type == "gene":
+		r.ID = geneID
+		r.Name = r.Attributes.Get("gene_name")
+	case r.Type == "transcript" || r.Type == "mRNA":
+		r.ID = transcriptID
+		r.Name = r.Attributes.Get("transcript_name")
+		if len(geneID) > 0 {
+			r.Parents = []string{geneID}
+		}
+	case len(transcriptID) > 0:
+		r.Parents = []string{transcriptID}
+	case len(geneID) > 0:
+		r.Parents = []string{geneID}
+	}
+	return &r, nil
+}
+
+// ParseGTF parses a GTF file, returning a slice of *Record with
+// Children linked through gene_id and transcript_id. Genes and
+// transcripts without their own lines are not linked; use
+// GTFToGFF3 to synthesize them.
+func ParseGTF(r io.Reader) ([]*Record, error) {
+	var records []*Record
+	var lineno int
+	input := bufio.NewReader(r)
+	for {
+		line, err := input.ReadString('\n')
+		if len(line) > 0 {
+			lineno++
+			if trimmed := strings.TrimSpace(line); len(trimmed) > 0 && !strings.HasPrefix(trimmed, "#") {
+				rec, perr := ParseGTFRecord(line)
+				if perr != nil {
+					return records, fmt.Errorf("gff: line %d: %w", lineno, perr)
+				}
+				records = append(records, rec)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return records, err
+		}
+	}
+	LinkChildren(records)
+	return records, nil
+}
+
+// gtfTypes maps GTF feature types to their GFF3 equivalents
+var gtfTypes = map[string]string{
+	"5UTR":            "five_prime_UTR",
+	"3UTR":            "three_prime_UTR",
+	"five_prime_utr":  "five_prime_UTR",
+	"three_prime_utr": "three_prime_UTR",
+}
+
+// gff3Types maps GFF3 feature types to their GTF equivalents
+var gff3Types = map[string]string{
+	"five_prime_UTR":  "five_prime_utr",
+	"three_prime_UTR": "three_prime_utr",
+}
+
+// span returns a new record covering the extent of recs
+func span(recs []*Record, typ string) *Record {
+	first := recs[0]
+	r := &Record{SeqID: first.SeqID, Source: first.Source, Type: typ,
+		Start: first.Start, End: first.End, Strand: first.Strand, Phase: "."}
+	for _, rec := range recs[1:] {
+		r.Start = min(r.Start, rec.Start)
+		r.End = max(r.End, rec.End)
+	}
+	return r
+}
+
+// copyAttributes copies the attributes of src to dst, except for
+// those with the given tags
+func copyAttributes(dst *Attributes, src Attributes, skip ...string) {
+	for _, attrib := range src {
+		var skipped bool
+		for _, tag := range skip {
+			skipped = skipped || attrib.Tag == tag
+		}
+		if !skipped {
+			dst.Add(attrib.Tag, attrib.Values...)
+		}
+	}
+}
+
+// gtfGene collects the records of one gene_id
+type gtfGene struct {
+	id          string
+	gene        *Record
+	transcripts []string
+	lines       map[string]*Record   // transcript lines, by ID
+	features    map[string][]*Record // features, by transcript ID
+}
+
+// GTFToGFF3 converts records parsed from a GTF file to GFF3 records
+// with ID and Parent attributes. Gene and transcript records
+// missing from the GTF are synthesized, spanning their features.
+// Transcripts with a CDS become mRNA records. The input records
+// are not modified; the results have their Children linked.
+func GTFToGFF3(recs []*Record) []*Record {
+	var genes []*gtfGene
+	var byID = make(map[string]*gtfGene)
+	for _, rec := range recs {
+		geneID := rec.Attributes.Get("gene_id")
+		g, ok := byID[geneID]
+		if !ok {
+			g = &gtfGene{id: geneID, lines: make(map[string]*Record),
+				features: make(map[string][]*Record)}
+			byID[geneID] = g
+			genes = append(genes, g)
+		}
+		if rec.Type == "gene" {
+			g.gene = rec
+			continue
+		}
+		txID := rec.Attributes.Get("transcript_id")
+		if _, ok := g.features[txID]; !ok {
+			g.transcripts = append(g.transcripts, txID)
+			g.features[txID] = nil
+		}
+		if rec.Type == "transcript" || rec.Type == "mRNA" {
+			g.lines[txID] = rec
+		} else {
+			g.features[txID] = append(g.features[txID], rec)
+		}
+	}
+
+	var out []*Record
+	for _, g := range genes {
+		var all []*Record
+		for _, txID := range g.transcripts {
+			if tx, ok := g.lines[txID]; ok {
+				all = append(all, tx)
+			}
+			all = append(all, g.features[txID]...)
+		}
+		var gene *Record
+		if g.gene != nil {
+			gene = span([]*Record{g.gene}, "gene")
+			gene.Score, gene.ScoreExists = g.gene.Score, g.gene.ScoreExists
+			copyAttributes(&gene.Attributes, g.gene.Attributes, "gene_id")
+		} else {
+			gene = span(all, "gene")
+		}
+		gene.ID = g.id
+		gene.IsGene = true
+		gene.Attributes = append(Attributes{{"ID", []string{g.id}}}, gene.Attributes...)
+		out = append(out, gene)
+
+		for _, txID := range g.transcripts {
+			parentID := g.id
+			if len(txID) > 0 {
+				var tx *Record
+				if line, ok := g.lines[txID]; ok {
+					tx = span([]*Record{line}, "transcript")
+					tx.Score, tx.ScoreExists = line.Score, line.ScoreExists
+					copyAttributes(&tx.Attributes, line.Attributes, "gene_id", "transcript_id")
+				} else {
+					tx = span(g.features[txID], "transcript")
+				}
+				for _, f := range g.features[txID] {
+					if f.Type == "CDS" {
+						tx.Type = "mRNA"
+					}
+				}
+				tx.ID, tx.Parents = txID, []string{g.id}
+				tx.Attributes = append(Attributes{{"ID", []string{txID}},
+					{"Parent", []string{g.id}}}, tx.Attributes...)
+				out = append(out, tx)
+				parentID = txID
+			}
+			for _, f := range g.features[txID] {
+				rec := span([]*Record{f}, f.Type)
+				if typ, ok := gtfTypes[f.Type]; ok {
+					rec.Type = typ
+				}
+				rec.Score, rec.ScoreExists = f.Score, f.ScoreExists
+				rec.Phase = f.Phase
+				rec.Parents = []string{parentID}
+				rec.Attributes = Attributes{{"Parent", []string{parentID}}}
+				copyAttributes(&rec.Attributes, f.Attributes, "gene_id", "transcript_id")
+				out = append(out, rec)
+			}
+		}
+	}
+	LinkChildren(out)
+	return out
+}
+
+// GFF3ToGTF converts GFF3 records, with Children linked, to GTF
+// records carrying gene_id and transcript_id attributes. Every
+// transcript (a record with exon or CDS children) is written with
+// its gene, synthesizing a gene record when the transcript has no
+// parent. Features outside any transcript are not included.
+func GFF3ToGTF(recs []*Record) []*Record {
+	var byID = make(map[string]*Record)
+	for _, rec := range recs {
+		if _, ok := byID[rec.ID]; !ok && rec.ID != "" {
+			byID[rec.ID] = rec
+		}
+	}
+	var out []*Record
+	var seenGenes = make(map[string]bool)
+	var seenTx = make(map[*Record]bool)
+	for _, rec := range recs {
+		for _, tx := range transcriptsOf(rec) {
+			if seenTx[tx] {
+				continue
+			}
+			seenTx[tx] = true
+			txID := transcriptID(tx)
+			geneID := txID
+			var gene *Record
+			if len(tx.Parents) > 0 && Classify(tx.Type) != GeneFeature {
+				geneID = tx.Parents[0]
+				gene = byID[geneID]
+			}
+			if !seenGenes[geneID] {
+				seenGenes[geneID] = true
+				var g *Record
+				if gene != nil {
+					g = span([]*Record{gene}, "gene")
+					g.Score, g.ScoreExists = gene.Score, gene.ScoreExists
+				} else {
+					g = span([]*Record{tx}, "gene")
+				}
+				g.ID, g.IsGene = geneID, true
+				g.Attributes = Attributes{{"gene_id", []string{geneID}}}
+				if gene != nil {
+					copyAttributes(&g.Attributes, gene.Attributes, "ID", "Parent")
+				}
+				out = append(out, g)
+			}
+			t := span([]*Record{tx}, "transcript")
+			t.Score, t.ScoreExists = tx.Score, tx.ScoreExists
+			t.ID, t.Parents = txID, []string{geneID}
+			t.Attributes = Attributes{{"gene_id", []string{geneID}},
+				{"transcript_id", []string{txID}}}
+			if tx != gene {
+				copyAttributes(&t.Attributes, tx.Attributes, "ID", "Parent")
+			}
+			out = append(out, t)
+			for _, f := range tx.Children {
+				rec := span([]*Record{f}, f.Type)
+				if typ, ok := gff3Types[f.Type]; ok {
+					rec.Type = typ
+				}
+				rec.Score, rec.ScoreExists = f.Score, f.ScoreExists
+				rec.Phase = f.Phase
+				rec.Parents = []string{txID}
+				rec.Attributes = Attributes{{"gene_id", []string{geneID}},
+					{"transcript_id", []string{txID}}}
+				copyAttributes(&rec.Attributes, f.Attributes, "ID", "Parent")
+				out = append(out, rec)
+			}
+		}
+	}
+	LinkChildren(out)
+	return out
+}
+
+// gtfAttributeString formats attributes as a GTF column 9 string
+func gtfAttributeString(a Attributes) string {
+	var attribstr strings.Builder
+	for _, attrib := range a {
+		for _, val := range attrib.Values {
+			if attribstr.Len() > 0 {
+				attribstr.WriteByte(' ')
+			}
+			attribstr.WriteString(attrib.Tag)
+			attribstr.WriteByte(' ')
+			attribstr.WriteString(strconv.Quote(val))
+			attribstr.WriteByte(';')
+		}
+	}
+	return attribstr.String()
+}
+
+// WriteGTFRecord writes a single record in GTF format
+func WriteGTFRecord(r *Record, w io.Writer) error {
+	noEscape := func(s string) string { return s }
+	_, err := io.WriteString(w, formatColumns(r, r.SeqID, noEscape)+
+		gtfAttributeString(r.Attributes)+"\n")
+	return err
+}
+
+// WriteGTF writes records in GTF format. The records should carry
+// gene_id and transcript_id attributes, as produced by GFF3ToGTF.
+func WriteGTF(recs []*Record, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, rec := range recs {
+		if err := WriteGTFRecord(rec, bw); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,132 @@
+package gff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pmagwene/biofiles/fasta"
+)
+
+// Directive is a "##name value" line of a GFF3 file
+type Directive struct {
+	Name  string
+	Value string
+}
+
+func (d Directive) String() string {
+	if len(d.Value) == 0 {
+		return "##" + d.Name
+	}
+	return "##" + d.Name + " " + d.Value
+}
+
+// parseDirective splits a directive line into its name and value
+func parseDirective(line string) Directive {
+	name, value, _ := strings.Cut(strings.TrimPrefix(line, "##"), " ")
+	return Directive{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)}
+}
+
+// SequenceRegion is the extent of a landmark sequence, as given by
+// a ##sequence-region directive
+type SequenceRegion struct {
+	SeqID string
+	Start int
+	End   int
+}
+
+// Header holds the directives of a GFF3 file
+type Header struct {
+	Version           string // ##gff-version, e.g. "3" or "3.1.26"
+	SequenceRegions   []SequenceRegion
+	FeatureOntology   string
+	AttributeOntology string
+	SourceOntology    string
+	Species           string // NCBI taxonomy URL
+	GenomeBuild       string // "source buildName"
+	Other             []Directive
+}
+
+// NewHeader returns a Header for GFF version 3
+func NewHeader() *Header {
+	return &Header{Version: "3"}
+}
+
+// SequenceRegion returns the sequence region of the given seqid
+func (h *Header) SequenceRegion(seqid string) (SequenceRegion, bool) {
+	for _, sr := range h.SequenceRegions {
+		if sr.SeqID == seqid {
+			return sr, true
+		}
+	}
+	return SequenceRegion{}, false
+}
+
+// addDirective records a directive in the header
+func (h *Header) addDirective(d Directive) error {
+	switch d.Name {
+	case "gff-version":
+		h.Version = d.Value
+	case "sequence-region":
+		fields := strings.Fields(d.Value)
+		if len(fields) != 3 {
+			return fmt.Errorf("invalid sequence-region %q", d.Value)
+		}
+		start, err1 := strconv.Atoi(fields[1])
+		end, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("invalid sequence-region %q", d.Value)
+		}
+		h.SequenceRegions = append(h.SequenceRegions,
+			SequenceRegion{unescape(fields[0]), start, end})
+	case "feature-ontology":
+		h.FeatureOntology = d.Value
+	case "attribute-ontology":
+		h.AttributeOntology = d.Value
+	case "source-ontology":
+		h.SourceOntology = d.Value
+	case "species":
+		h.Species = d.Value
+	case "genome-build":
+		h.GenomeBuild = d.Value
+	default:
+		h.Other = append(h.Other, d)
+	}
+	return nil
+}
+
+// Directives returns the directives of the header, other than
+// ##gff-version, in the order they are written
+func (h *Header) Directives() []Directive {
+	var ds []Directive
+	for _, sr := range h.SequenceRegions {
+		ds = append(ds, Directive{"sequence-region",
+			fmt.Sprintf("%s %d %d", escapeSeqID(sr.SeqID), sr.Start, sr.End)})
+	}
+	for _, d := range []Directive{
+		{"feature-ontology", h.FeatureOntology},
+		{"attribute-ontology", h.AttributeOntology},
+		{"source-ontology", h.SourceOntology},
+		{"species", h.Species},
+		{"genome-build", h.GenomeBuild},
+	} {
+		if len(d.Value) > 0 {
+			ds = append(ds, d)
+		}
+	}
+	return append(ds, h.Other...)
+}
+
+// File is the parsed contents of a GFF3 file
+type File struct {
+	Header  *Header
+	Records []*Record
+
+	// Barriers holds the positions of ### directives: each is the
+	// index in Records of the first record following the directive,
+	// repeated for consecutive directives
+	Barriers []int
+
+	// Fasta holds the sequences of the ##FASTA section, if any
+	Fasta []*fasta.Record
+}
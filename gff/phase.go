@@ -0,0 +1,110 @@
+package gff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// PhaseMismatch describes a CDS whose phase column disagrees with
+// the phase computed from the lengths of the preceding segments
+type PhaseMismatch struct {
+	Record   *Record
+	Phase    string // phase as given in the file
+	Expected int
+}
+
+func (m PhaseMismatch) String() string {
+	return fmt.Sprintf("%s %s:%d..%d phase %s, expected %d", m.Record.Type,
+		m.Record.SeqID, m.Record.Start, m.Record.End, m.Phase, m.Expected)
+}
+
+// ComputePhases returns the correct phase of each of the CDS
+// segments of a single transcript, in the same order as cds. The
+// segments are taken in 5' to 3' order: ascending coordinates on
+// the plus strand and descending on the minus strand. The phase of
+// the 5'-most segment is kept if it is valid, as for a CDS that is
+// incomplete at its 5' end, and is taken as 0 otherwise; each
+// following phase is the number of bases needed to complete the
+// codon left open by the previous segment.
+func ComputePhases(cds []*Record) ([]int, error) {
+	if len(cds) == 0 {
+		return nil, nil
+	}
+	strand := cds[0].Strand
+	if strand != "+" && strand != "-" {
+		return nil, fmt.Errorf("gff: CDS %s:%d..%d has strand %q",
+			cds[0].SeqID, cds[0].Start, cds[0].End, strand)
+	}
+	order := make([]int, len(cds))
+	for i, rec := range cds {
+		if rec.Strand != strand || rec.SeqID != cds[0].SeqID {
+			return nil, fmt.Errorf("gff: CDS segments on different strands or sequences")
+		}
+		if rec.Start > rec.End {
+			return nil, fmt.Errorf("gff: CDS %s:%d..%d has start after end",
+				rec.SeqID, rec.Start, rec.End)
+		}
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if strand == "-" {
+			return cds[order[i]].End > cds[order[j]].End
+		}
+		return cds[order[i]].Start < cds[order[j]].Start
+	})
+	phases := make([]int, len(cds))
+	phase, err := strconv.Atoi(cds[order[0]].Phase)
+	if err != nil || phase < 0 || phase > 2 {
+		phase = 0
+	}
+	for _, i := range order {
+		phases[i] = phase
+		length := cds[i].End - cds[i].Start + 1
+		phase = (3 - (length-phase)%3) % 3
+	}
+	return phases, nil
+}
+
+// transcriptPhases calls fn with each CDS of each transcript of
+// rec and its computed phase
+func transcriptPhases(rec *Record, fn func(cds *Record, phase int)) error {
+	for _, t := range transcriptsOf(rec) {
+		cds := childrenOfClass(t, CDSFeature)
+		phases, err := ComputePhases(cds)
+		if err != nil {
+			return fmt.Errorf("%w in transcript %s", err, transcriptID(t))
+		}
+		for i, p := range phases {
+			fn(cds[i], p)
+		}
+	}
+	return nil
+}
+
+// CheckPhases compares the phases of the CDS of a transcript
+// record, or of each transcript of a gene record, with those given
+// by ComputePhases. Children must be populated, as done by
+// ParseAll.
+func CheckPhases(rec *Record) ([]PhaseMismatch, error) {
+	var mismatches []PhaseMismatch
+	err := transcriptPhases(rec, func(cds *Record, phase int) {
+		if cds.Phase != strconv.Itoa(phase) {
+			mismatches = append(mismatches, PhaseMismatch{cds, cds.Phase, phase})
+		}
+	})
+	return mismatches, err
+}
+
+// RepairPhases is like CheckPhases, but also rewrites the Phase of
+// each mismatched CDS with its computed value
+func RepairPhases(rec *Record) ([]PhaseMismatch, error) {
+	var mismatches []PhaseMismatch
+	err := transcriptPhases(rec, func(cds *Record, phase int) {
+		if p := strconv.Itoa(phase); cds.Phase != p {
+			mismatches = append(mismatches, PhaseMismatch{cds, cds.Phase, phase})
+			cds.Phase = p
+		}
+	})
+	return mismatches, err
+}
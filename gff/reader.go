@@ -0,0 +1,240 @@
+package gff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/pmagwene/biofiles/fasta"
+)
+
+// ParseError reports the location and reason of a GFF parsing
+// failure. Line numbers are 1-based.
+type ParseError struct {
+	Line int
+	Text string // the offending line
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("gff: line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads GFF3 records one at a time from an io.Reader.
+// Directives are collected into a Header as they are read, and
+// reading stops at the start of a FASTA section, which can then be
+// read with Fasta.
+//
+// Records can be read individually with Next, or as groups of
+// related features with NextGroup, but the two should not be mixed.
+//
+// By default a malformed line stops the Reader with a *ParseError;
+// calling Next again resumes reading at the following line. If
+// SkipInvalid is set, malformed lines are skipped instead.
+type Reader struct {
+	SkipInvalid bool
+
+	r        *bufio.Reader
+	header   *Header
+	rec      *Record
+	text     string
+	line     int
+	recLine  int
+	err      error
+	barriers int       // number of ### directives before the current record
+	fasta    io.Reader // the FASTA section, once reached
+	pending  *Record   // first record of the next group
+	groupErr error     // error to report after the current group
+	group    []*Record
+}
+
+// NewReader returns a new Reader that reads from r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), header: new(Header)}
+}
+
+// Header returns the directives read so far. Directives that
+// precede the first record are available after the first call to
+// Next or NextGroup.
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// error returns a *ParseError for the current line
+func (r *Reader) error(text string, err error) error {
+	return &ParseError{Line: r.line, Text: strings.TrimRight(text, "\r\n"), Err: err}
+}
+
+// Next advances the Reader to the next record, which is then
+// available through Record. It returns false when there are no
+// more records, either because the end of the records or an error
+// was reached; Err distinguishes the two cases.
+func (r *Reader) Next() bool {
+	r.rec = nil
+	r.barriers = 0
+	if _, ok := r.err.(*ParseError); ok {
+		r.err = nil
+	}
+	if r.err != nil {
+		return false
+	}
+	for {
+		text, err := r.r.ReadString('\n')
+		if len(text) == 0 && err != nil {
+			r.err = err
+			return false
+		}
+		r.line++
+		line := strings.TrimSpace(text)
+		switch {
+		case len(line) == 0:
+			continue
+		case line == "###":
+			r.barriers++
+			continue
+		case strings.HasPrefix(line, "##FASTA"):
+			r.fasta = r.r
+			r.err = io.EOF
+			return false
+		case strings.HasPrefix(line, ">"):
+			// A FASTA section may start without a directive
+			r.fasta = io.MultiReader(strings.NewReader(text), r.r)
+			r.err = io.EOF
+			return false
+		case strings.HasPrefix(line, "##"):
+			if err := r.header.addDirective(parseDirective(line)); err != nil && !r.SkipInvalid {
+				r.err = r.error(text, err)
+				return false
+			}
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+		rec, err := ParseRecord(line)
+		if err != nil {
+			if r.SkipInvalid {
+				continue
+			}
+			r.err = r.error(text, err)
+			return false
+		}
+		r.rec, r.text, r.recLine = rec, text, r.line
+		return true
+	}
+}
+
+// Record returns the most recent record read by a call to Next
+func (r *Reader) Record() *Record {
+	return r.rec
+}
+
+// Text returns the line of input of the most recent record,
+// without unescaping
+func (r *Reader) Text() string {
+	return strings.TrimRight(r.text, "\r\n")
+}
+
+// Line returns the line number of the most recent record
+func (r *Reader) Line() int {
+	return r.recLine
+}
+
+// Err returns the first non-EOF error encountered by the Reader
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// Records returns an iterator over the remaining records in the
+// input. Iteration stops after the first error, which is yielded
+// with a nil Record.
+func (r *Reader) Records() iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		for r.Next() {
+			if !yield(r.Record(), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// NextGroup advances the Reader to the next group of records,
+// which is then available through Group. A group ends at a ###
+// directive, at a change of seqid, or at the end of the records,
+// and has its Children linked. Only references within the group
+// are linked, so files should use ### to separate features at
+// points where no forward references are outstanding. A group cut
+// short by an error is still returned, and the error is reported
+// by the following call.
+func (r *Reader) NextGroup() bool {
+	r.group = nil
+	if r.groupErr != nil {
+		r.err, r.groupErr = r.groupErr, nil
+		return false
+	}
+	var group []*Record
+	if r.pending != nil {
+		group = append(group, r.pending)
+		r.pending = nil
+	}
+	for r.Next() {
+		rec := r.Record()
+		if len(group) > 0 && (r.barriers > 0 || rec.SeqID != group[0].SeqID) {
+			r.pending = rec
+			break
+		}
+		group = append(group, rec)
+	}
+	if len(group) == 0 {
+		return false
+	}
+	if r.Err() != nil {
+		// report the error after returning the records before it
+		r.groupErr, r.err = r.err, nil
+	}
+	LinkChildren(group)
+	r.group = group
+	return true
+}
+
+// Group returns the most recent group read by a call to NextGroup
+func (r *Reader) Group() []*Record {
+	return r.group
+}
+
+// Groups returns an iterator over the remaining groups of records
+// in the input, as read by NextGroup. Iteration stops after the
+// first error, which is yielded with a nil group.
+func (r *Reader) Groups() iter.Seq2[[]*Record, error] {
+	return func(yield func([]*Record, error) bool) {
+		for r.NextGroup() {
+			if !yield(r.Group(), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// Fasta returns a reader for the FASTA section of the input, or
+// nil if the end of the records has not been reached or there is
+// no FASTA section
+func (r *Reader) Fasta() *fasta.Reader {
+	if r.fasta == nil {
+		return nil
+	}
+	return fasta.NewReader(r.fasta)
+}
@@ -0,0 +1,158 @@
+package gff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pmagwene/biofiles/fasta"
+)
+
+// transcriptsOf returns rec itself if it has exon or CDS children,
+// and otherwise those of its children that do, as for a gene.
+// Features are classified as by Classify.
+func transcriptsOf(rec *Record) []*Record {
+	hasParts := func(r *Record) bool {
+		for _, child := range r.Children {
+			if class := Classify(child.Type); class == ExonFeature || class == CDSFeature {
+				return true
+			}
+		}
+		return false
+	}
+	if hasParts(rec) {
+		return []*Record{rec}
+	}
+	var transcripts []*Record
+	for _, child := range rec.Children {
+		if hasParts(child) {
+			transcripts = append(transcripts, child)
+		}
+	}
+	return transcripts
+}
+
+// splice concatenates the sequences of parts, which must be sorted
+// by start, reverse complementing the result for features on the
+// minus strand. It also returns the coordinates of the parts.
+func splice(parts []*Record, fastadict map[string]*fasta.Record) (string, string, error) {
+	var seq strings.Builder
+	var coords []string
+	for _, part := range parts {
+		target, ok := fastadict[part.SeqID]
+		if !ok {
+			return "", "", fmt.Errorf("gff: no sequence for %s", part.SeqID)
+		}
+		if part.Start < 1 || part.End > len(target.Sequence) || part.Start > part.End {
+			return "", "", fmt.Errorf("gff: %s %d..%d outside sequence %s",
+				part.Type, part.Start, part.End, part.SeqID)
+		}
+		seq.WriteString(target.Sequence[part.Start-1 : part.End])
+		coords = append(coords, fmt.Sprintf("%d..%d", part.Start, part.End))
+	}
+	s := seq.String()
+	if parts[0].Strand == "-" {
+		s = fasta.ReverseComplement(s)
+	}
+	return s, parts[0].SeqID + ":" + strings.Join(coords, ","), nil
+}
+
+// transcriptID returns an ID for a transcript record
+func transcriptID(r *Record) string {
+	if len(r.ID) > 0 {
+		return r.ID
+	}
+	return fmt.Sprintf("%s_%s_%s_%d_%d", r.SeqID, r.Source, r.Type, r.Start, r.End)
+}
+
+// splicedCDS returns the CDS sequence of a single transcript, with
+// the bases given by the phase of the 5'-most CDS segment removed
+func splicedCDS(t *Record, fastadict map[string]*fasta.Record) (*fasta.Record, int, error) {
+	cds := childrenOfClass(t, CDSFeature)
+	if len(cds) == 0 {
+		return nil, 0, fmt.Errorf("gff: transcript %s has no CDS", transcriptID(t))
+	}
+	seq, coords, err := splice(cds, fastadict)
+	if err != nil {
+		return nil, 0, err
+	}
+	first := cds[0]
+	if first.Strand == "-" {
+		first = cds[len(cds)-1]
+	}
+	phase, err := strconv.Atoi(first.Phase)
+	if err != nil || phase < 0 || phase > 2 {
+		phase = 0
+	}
+	seq = seq[min(phase, len(seq)):]
+	return &fasta.Record{ID: transcriptID(t), Description: coords, Sequence: seq}, phase, nil
+}
+
+// SplicedTranscripts returns the spliced transcript sequence of an
+// mRNA (or other transcript) record, or of each transcript of a
+// gene record. Exons are joined in order and reverse complemented
+// for transcripts on the minus strand; transcripts without exons
+// are built from their CDS features. Parts are classified as by
+// Classify, so Sequence Ontology synonyms such as coding_exon are
+// accepted. Children must be populated, as done by ParseAll.
+// fastadict maps sequence IDs to the genome sequences, as returned
+// by fasta.ToMap.
+func SplicedTranscripts(rec *Record, fastadict map[string]*fasta.Record) ([]*fasta.Record, error) {
+	var seqs []*fasta.Record
+	for _, t := range transcriptsOf(rec) {
+		parts := childrenOfClass(t, ExonFeature)
+		if len(parts) == 0 {
+			parts = childrenOfClass(t, CDSFeature)
+		}
+		seq, coords, err := splice(parts, fastadict)
+		if err != nil {
+			return seqs, err
+		}
+		seqs = append(seqs, &fasta.Record{ID: transcriptID(t),
+			Description: coords, Sequence: seq})
+	}
+	return seqs, nil
+}
+
+// SplicedCDS returns the spliced coding sequence of a transcript
+// record, or of each coding transcript of a gene record. Leading
+// bases are removed according to the phase of the first CDS.
+func SplicedCDS(rec *Record, fastadict map[string]*fasta.Record) ([]*fasta.Record, error) {
+	var seqs []*fasta.Record
+	for _, t := range transcriptsOf(rec) {
+		if len(childrenOfClass(t, CDSFeature)) == 0 {
+			continue
+		}
+		seq, _, err := splicedCDS(t, fastadict)
+		if err != nil {
+			return seqs, err
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs, nil
+}
+
+// Proteins returns the translated protein sequence of a transcript
+// record, or of each coding transcript of a gene record, using the
+// given NCBI genetic code table. An alternative start codon at the
+// beginning of a CDS with phase 0 is translated as methionine, and
+// a terminal stop codon is dropped.
+func Proteins(rec *Record, fastadict map[string]*fasta.Record, table int) ([]*fasta.Record, error) {
+	var seqs []*fasta.Record
+	for _, t := range transcriptsOf(rec) {
+		if len(childrenOfClass(t, CDSFeature)) == 0 {
+			continue
+		}
+		cds, phase, err := splicedCDS(t, fastadict)
+		if err != nil {
+			return seqs, err
+		}
+		protein, err := cds.Translate(fasta.TranslateOptions{Table: table, Start: phase == 0})
+		if err != nil {
+			return seqs, err
+		}
+		protein.Sequence = strings.TrimSuffix(protein.Sequence, "*")
+		seqs = append(seqs, protein)
+	}
+	return seqs, nil
+}
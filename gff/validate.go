@@ -0,0 +1,243 @@
+package gff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Category classifies the problems found by Validate
+type Category int
+
+// Diagnostic categories
+const (
+	MalformedLine Category = iota
+	InvalidCoordinates
+	OutsideSequenceRegion
+	DuplicateID
+	InvalidPhase
+	InvalidStrand
+	UnescapedCharacter
+	UndefinedParent
+	OutsideParent
+)
+
+func (c Category) String() string {
+	switch c {
+	case MalformedLine:
+		return "malformed line"
+	case InvalidCoordinates:
+		return "invalid coordinates"
+	case OutsideSequenceRegion:
+		return "outside sequence-region"
+	case DuplicateID:
+		return "duplicate ID"
+	case InvalidPhase:
+		return "invalid phase"
+	case InvalidStrand:
+		return "invalid strand"
+	case UnescapedCharacter:
+		return "unescaped character"
+	case UndefinedParent:
+		return "undefined Parent"
+	case OutsideParent:
+		return "outside parent"
+	}
+	return fmt.Sprintf("Category(%d)", int(c))
+}
+
+// Diagnostic is a single problem found in a GFF3 file
+type Diagnostic struct {
+	Category Category
+	Line     int
+	Record   *Record // nil for malformed lines
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("line %d: %s: %s", d.Line, d.Category, d.Message)
+}
+
+// validator accumulates diagnostics for a file
+type validator struct {
+	diags []Diagnostic
+	lines map[*Record]int
+}
+
+func (v *validator) add(c Category, rec *Record, format string, args ...any) {
+	v.diags = append(v.diags, Diagnostic{Category: c, Line: v.lines[rec],
+		Record: rec, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate reads a GFF3 file and checks it against the
+// specification, returning diagnostics ordered by line number.
+// Malformed lines are reported and skipped. The returned error is
+// non-nil only if the input could not be read.
+func Validate(r io.Reader) ([]Diagnostic, error) {
+	v := &validator{lines: make(map[*Record]int)}
+	var recs []*Record
+	reader := NewReader(r)
+	for {
+		if !reader.Next() {
+			var perr *ParseError
+			if errors.As(reader.Err(), &perr) {
+				v.diags = append(v.diags, Diagnostic{Category: MalformedLine,
+					Line: perr.Line, Message: perr.Err.Error()})
+				continue
+			}
+			if err := reader.Err(); err != nil {
+				return v.diags, err
+			}
+			break
+		}
+		rec := reader.Record()
+		v.lines[rec] = reader.Line()
+		recs = append(recs, rec)
+		v.checkRecord(rec)
+		v.checkEscapes(rec, reader.Text())
+	}
+	v.checkIDs(recs)
+	v.checkRegions(recs, reader.Header())
+	v.checkHierarchy(recs)
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		return v.diags[i].Line < v.diags[j].Line
+	})
+	return v.diags, nil
+}
+
+// checkRecord checks the columns of a single record
+func (v *validator) checkRecord(rec *Record) {
+	if rec.Start < 1 || rec.Start > rec.End {
+		v.add(InvalidCoordinates, rec, "start %d, end %d", rec.Start, rec.End)
+	}
+	switch rec.Strand {
+	case "+", "-", ".", "?":
+	default:
+		v.add(InvalidStrand, rec, "strand %q", rec.Strand)
+	}
+	if Classify(rec.Type) == CDSFeature {
+		switch rec.Phase {
+		case "0", "1", "2":
+		case ".", "":
+			v.add(InvalidPhase, rec, "CDS has no phase")
+		default:
+			v.add(InvalidPhase, rec, "CDS phase %q", rec.Phase)
+		}
+	} else if rec.Phase != "." && rec.Phase != "0" && rec.Phase != "1" && rec.Phase != "2" {
+		v.add(InvalidPhase, rec, "phase %q", rec.Phase)
+	}
+}
+
+// badEscape returns the position of the first '%' in s that does
+// not begin a valid escape, or -1
+func badEscape(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		if i+2 >= len(s) {
+			return i
+		}
+		if _, ok := unhex(s[i+1]); !ok {
+			return i
+		}
+		if _, ok := unhex(s[i+2]); !ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkEscapes checks the raw text of a record for reserved
+// characters that should have been escaped
+func (v *validator) checkEscapes(rec *Record, text string) {
+	cols := strings.Split(text, "\t")
+	if len(cols) != 9 {
+		return
+	}
+	for i, col := range cols {
+		if pos := badEscape(col); pos >= 0 {
+			v.add(UnescapedCharacter, rec, "'%%' in column %d", i+1)
+		}
+		for j := 0; j < len(col); j++ {
+			if col[j] < 0x20 || col[j] == 0x7f {
+				v.add(UnescapedCharacter, rec, "control character %q in column %d", col[j], i+1)
+				break
+			}
+		}
+	}
+	for j := 0; j < len(cols[0]); j++ {
+		if c := cols[0][j]; c != '%' && !isSeqIDChar(c) {
+			v.add(UnescapedCharacter, rec, "%q in seqid", c)
+			break
+		}
+	}
+	if cols[8] == "." {
+		return
+	}
+	for _, field := range strings.Split(cols[8], ";") {
+		if len(strings.TrimSpace(field)) == 0 {
+			continue
+		}
+		tag, value, ok := strings.Cut(field, "=")
+		switch {
+		case !ok:
+			v.add(UnescapedCharacter, rec, "attribute %q has no '='", field)
+		case strings.Contains(value, "="):
+			v.add(UnescapedCharacter, rec, "'=' in value of %s", tag)
+		case strings.Contains(field, "&"):
+			v.add(UnescapedCharacter, rec, "'&' in attribute %s", tag)
+		}
+	}
+}
+
+// checkIDs reports IDs that occur on non-contiguous lines. Lines of
+// a multi-line feature share an ID, but should be adjacent.
+func (v *validator) checkIDs(recs []*Record) {
+	last := make(map[string]int)
+	for i, rec := range recs {
+		if len(rec.ID) == 0 {
+			continue
+		}
+		if j, ok := last[rec.ID]; ok && j != i-1 {
+			v.add(DuplicateID, rec, "ID %s also used on line %d", rec.ID, v.lines[recs[j]])
+		}
+		last[rec.ID] = i
+	}
+}
+
+// checkRegions reports records outside their ##sequence-region
+func (v *validator) checkRegions(recs []*Record, h *Header) {
+	if len(h.SequenceRegions) == 0 {
+		return
+	}
+	for _, rec := range recs {
+		sr, ok := h.SequenceRegion(rec.SeqID)
+		if ok && (rec.Start < sr.Start || rec.End > sr.End) {
+			v.add(OutsideSequenceRegion, rec, "%d..%d outside %s %d..%d",
+				rec.Start, rec.End, sr.SeqID, sr.Start, sr.End)
+		}
+	}
+}
+
+// checkHierarchy reports undefined parents, and children that are
+// not contained within their parents
+func (v *validator) checkHierarchy(recs []*Record) {
+	var missing *MissingParentError
+	if errors.As(LinkChildren(recs), &missing) {
+		for _, ref := range missing.Refs {
+			v.add(UndefinedParent, ref.Record, "Parent %s is not defined", ref.Parent)
+		}
+	}
+	for _, rec := range recs {
+		for _, child := range rec.Children {
+			if child.SeqID != rec.SeqID || child.Start < rec.Start || child.End > rec.End {
+				v.add(OutsideParent, child, "%s %s:%d..%d extends beyond parent %s %s:%d..%d",
+					child.Type, child.SeqID, child.Start, child.End,
+					rec.ID, rec.SeqID, rec.Start, rec.End)
+			}
+		}
+	}
+}
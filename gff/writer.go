@@ -0,0 +1,193 @@
+package gff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pmagwene/biofiles/fasta"
+)
+
+// Writer writes GFF3 records to an io.Writer. The required
+// "##gff-version 3" line is written before any other output.
+// Output is buffered; Flush must be called once all records
+// have been written.
+type Writer struct {
+	w           *bufio.Writer
+	wroteHeader bool
+}
+
+// NewWriter returns a new Writer that writes to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) writeHeader() {
+	if !w.wroteHeader {
+		w.w.WriteString("##gff-version 3\n")
+		w.wroteHeader = true
+	}
+}
+
+// WriteHeader writes the ##gff-version line and other directives
+// of h. It must be called before any other output, and returns an
+// error otherwise.
+func (w *Writer) WriteHeader(h *Header) error {
+	if w.wroteHeader {
+		return fmt.Errorf("gff: header written after other output")
+	}
+	version := h.Version
+	if len(version) == 0 {
+		version = "3"
+	}
+	w.wroteHeader = true
+	if _, err := w.w.WriteString("##gff-version " + version + "\n"); err != nil {
+		return err
+	}
+	for _, d := range h.Directives() {
+		if _, err := w.w.WriteString(d.String() + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteBarrier writes a ### directive, indicating that all forward
+// references to features written so far have been resolved
+func (w *Writer) WriteBarrier() error {
+	w.writeHeader()
+	_, err := w.w.WriteString("###\n")
+	return err
+}
+
+// WriteDirective writes a "##name value" directive line
+func (w *Writer) WriteDirective(name string, value string) error {
+	w.writeHeader()
+	w.w.WriteString("##")
+	w.w.WriteString(name)
+	if len(value) > 0 {
+		w.w.WriteByte(' ')
+		w.w.WriteString(value)
+	}
+	_, err := w.w.WriteString("\n")
+	return err
+}
+
+// formatColumns returns the first eight columns of a record,
+// each followed by a tab. escape is applied to the text columns.
+func formatColumns(r *Record, seqid string, escape func(string) string) string {
+	column := func(s string) string {
+		if len(s) == 0 {
+			return "."
+		}
+		return escape(s)
+	}
+	scorestr := "."
+	if r.ScoreExists {
+		scorestr = strconv.FormatFloat(r.Score, 'g', -1, 64)
+	}
+	return strings.Join([]string{
+		seqid, column(r.Source), column(r.Type),
+		strconv.Itoa(r.Start), strconv.Itoa(r.End), scorestr,
+		column(r.Strand), column(r.Phase), ""}, "\t")
+}
+
+// Write writes a single record
+func (w *Writer) Write(r *Record) error {
+	w.writeHeader()
+	w.w.WriteString(formatColumns(r, escapeSeqID(r.SeqID), escapeColumn))
+	w.w.WriteString(r.Attributes.String())
+	_, err := w.w.WriteString("\n")
+	return err
+}
+
+// WriteAll writes a slice of records and flushes the Writer
+func (w *Writer) WriteAll(recs []*Record) error {
+	for _, rec := range recs {
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// WriteFasta writes a ##FASTA section holding the given sequences.
+// No further records may be written after the FASTA section, and
+// the Writer is flushed once it has been written.
+func (w *Writer) WriteFasta(fastarecs []*fasta.Record) error {
+	if err := w.WriteDirective("FASTA", ""); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := fasta.NewWriter(w.w).WriteAll(fastarecs); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// WriteFile writes the header, records, ### directives and FASTA
+// section of f to the given Writer
+func WriteFile(f *File, w io.Writer) error {
+	gw := NewWriter(w)
+	if f.Header != nil {
+		if err := gw.WriteHeader(f.Header); err != nil {
+			return err
+		}
+	}
+	barriers := f.Barriers
+	for i, rec := range f.Records {
+		for len(barriers) > 0 && barriers[0] <= i {
+			if err := gw.WriteBarrier(); err != nil {
+				return err
+			}
+			barriers = barriers[1:]
+		}
+		if err := gw.Write(rec); err != nil {
+			return err
+		}
+	}
+	for range barriers {
+		if err := gw.WriteBarrier(); err != nil {
+			return err
+		}
+	}
+	if len(f.Fasta) > 0 {
+		return gw.WriteFasta(f.Fasta)
+	}
+	return gw.Flush()
+}
+
+// WriteRecord writes a single GFF record to the given Writer,
+// without a ##gff-version header
+func WriteRecord(r *Record, w io.Writer) error {
+	gw := NewWriter(w)
+	gw.wroteHeader = true
+	if err := gw.Write(r); err != nil {
+		return err
+	}
+	return gw.Flush()
+}
+
+// WriteAll writes the ##gff-version header and string
+// representations of GFF records to given Writer interface
+func WriteAll(recs []*Record, w io.Writer) error {
+	return NewWriter(w).WriteAll(recs)
+}
+
+// WriteFastaSection appends an optional Fasta section to a GFF file.
+// WriteFastaSection should be called after gff.Write or gff.WriteAll
+func WriteFastaSection(fastarecs []*fasta.Record, w io.Writer) error {
+	if _, err := io.WriteString(w, "##FASTA\n"); err != nil {
+		return err
+	}
+	return fasta.WriteAll(fastarecs, w)
+}
@@ -0,0 +1,211 @@
+// Package interval provides genomic intervals with explicit
+// coordinate systems, set operations on them, and an index for
+// fast overlap and proximity queries over features
+package interval
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CoordSystem is the convention by which the Start and End of an
+// Interval locate the bases of a feature
+type CoordSystem int
+
+// Coordinate systems. In 1-based closed coordinates, as used by
+// GFF, VCF and samtools regions, the first base of a sequence is 1
+// and End is the last base of the feature. In 0-based half-open
+// coordinates, as used by BED, the first base is 0 and End is one
+// past the last base. The bases 1-100 in one are 0-100 in the other.
+const (
+	OneBased  CoordSystem = iota // 1-based, closed
+	ZeroBased                    // 0-based, half-open
+)
+
+func (c CoordSystem) String() string {
+	if c == ZeroBased {
+		return "0-based half-open"
+	}
+	return "1-based closed"
+}
+
+// Interval is the position of a feature on a sequence. The zero
+// value of Coords is OneBased, so Start and End are 1-based and
+// closed unless Coords says otherwise.
+type Interval struct {
+	SeqID  string
+	Start  int
+	End    int
+	Strand byte // '+', '-', or 0 for unstranded features
+	Coords CoordSystem
+}
+
+// In returns the interval converted to the coordinate system c
+func (iv Interval) In(c CoordSystem) Interval {
+	switch {
+	case iv.Coords == c:
+	case c == ZeroBased:
+		iv.Start--
+	default:
+		iv.Start++
+	}
+	iv.Coords = c
+	return iv
+}
+
+// half returns the 0-based half-open bounds of the interval
+func (iv Interval) half() (int, int) {
+	z := iv.In(ZeroBased)
+	return z.Start, z.End
+}
+
+// withBounds returns a copy of iv with the 0-based half-open bounds
+// start and end, in the coordinate system of iv
+func (iv Interval) withBounds(start, end int) Interval {
+	coords := iv.Coords
+	iv.Start, iv.End, iv.Coords = start, end, ZeroBased
+	return iv.In(coords)
+}
+
+// floor returns the smallest valid start position, 0 or 1
+func (iv Interval) floor() int {
+	if iv.Coords == ZeroBased {
+		return 0
+	}
+	return 1
+}
+
+// Len returns the number of bases in the interval
+func (iv Interval) Len() int {
+	start, end := iv.half()
+	return end - start
+}
+
+// String returns the interval as a 1-based region string, such as
+// "chr1:100-200", followed by the strand in parentheses if set
+func (iv Interval) String() string {
+	o := iv.In(OneBased)
+	s := fmt.Sprintf("%s:%d-%d", o.SeqID, o.Start, o.End)
+	if iv.Strand != 0 {
+		s += "(" + string(iv.Strand) + ")"
+	}
+	return s
+}
+
+// Overlaps reports whether iv and o share at least one base. The
+// intervals may be in different coordinate systems; strand is
+// ignored.
+func (iv Interval) Overlaps(o Interval) bool {
+	a, b := iv.In(ZeroBased), o.In(ZeroBased)
+	return a.SeqID == b.SeqID && a.Start < b.End && b.Start < a.End
+}
+
+// Contains reports whether o lies entirely within iv
+func (iv Interval) Contains(o Interval) bool {
+	a, b := iv.In(ZeroBased), o.In(ZeroBased)
+	return a.SeqID == b.SeqID && a.Start <= b.Start && b.End <= a.End
+}
+
+// Intersect returns the bases common to iv and o, in the coordinate
+// system of iv. It returns false if they do not overlap.
+func (iv Interval) Intersect(o Interval) (Interval, bool) {
+	if !iv.Overlaps(o) {
+		return Interval{}, false
+	}
+	start, end := iv.half()
+	ostart, oend := o.half()
+	return iv.withBounds(max(start, ostart), min(end, oend)), true
+}
+
+// Union returns the interval spanning iv and o, in the coordinate
+// system of iv. It returns false unless they overlap or are
+// adjacent, since the union would not then be a single interval.
+func (iv Interval) Union(o Interval) (Interval, bool) {
+	start, end := iv.half()
+	ostart, oend := o.half()
+	if iv.SeqID != o.SeqID || ostart > end || start > oend {
+		return Interval{}, false
+	}
+	return iv.withBounds(min(start, ostart), max(end, oend)), true
+}
+
+// Subtract returns the parts of iv not covered by o: none, one or
+// two intervals
+func (iv Interval) Subtract(o Interval) []Interval {
+	if !iv.Overlaps(o) {
+		return []Interval{iv}
+	}
+	start, end := iv.half()
+	ostart, oend := o.half()
+	var parts []Interval
+	if start < ostart {
+		parts = append(parts, iv.withBounds(start, ostart))
+	}
+	if oend < end {
+		parts = append(parts, iv.withBounds(oend, end))
+	}
+	return parts
+}
+
+// Slop returns iv extended by left bases at its start and right
+// bases at its end, without extending before the start of the
+// sequence. Left and right refer to coordinates, not strand: for a
+// '-' strand feature, upstream is to the right.
+func (iv Interval) Slop(left, right int) Interval {
+	iv.Start = max(iv.Start-left, iv.floor())
+	iv.End += right
+	return iv
+}
+
+// Flank returns the intervals of left bases before iv and right
+// bases after it, omitting any that are empty once clipped to the
+// start of the sequence
+func (iv Interval) Flank(left, right int) []Interval {
+	start, end := iv.half()
+	var flanks []Interval
+	if lstart := max(start-left, 0); lstart < start {
+		flanks = append(flanks, iv.withBounds(lstart, start))
+	}
+	if right > 0 {
+		flanks = append(flanks, iv.withBounds(end, end+right))
+	}
+	return flanks
+}
+
+// Merge combines overlapping intervals, and those separated by at
+// most gap bases, returning them sorted by sequence ID and start in
+// the coordinate system of the first interval. A merged interval
+// keeps the strand of its parts if they all agree. The input slice
+// is not modified.
+func Merge(ivs []Interval, gap int) []Interval {
+	if len(ivs) == 0 {
+		return nil
+	}
+	coords := ivs[0].Coords
+	sorted := make([]Interval, len(ivs))
+	for i, iv := range ivs {
+		sorted[i] = iv.In(ZeroBased)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].SeqID != sorted[j].SeqID {
+			return sorted[i].SeqID < sorted[j].SeqID
+		}
+		return sorted[i].Start < sorted[j].Start
+	})
+	var merged []Interval
+	for _, iv := range sorted {
+		if n := len(merged); n > 0 && merged[n-1].SeqID == iv.SeqID && iv.Start <= merged[n-1].End+gap {
+			last := &merged[n-1]
+			last.End = max(last.End, iv.End)
+			if last.Strand != iv.Strand {
+				last.Strand = 0
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	for i := range merged {
+		merged[i] = merged[i].In(coords)
+	}
+	return merged
+}
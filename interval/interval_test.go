@@ -0,0 +1,71 @@
+package interval
+
+import (
+	"fmt"
+)
+
+type gene struct {
+	name   string
+	chrom  string
+	start  int
+	end    int
+	strand byte
+}
+
+func locate(g gene) Interval {
+	return Interval{g.chrom, g.start, g.end, g.strand, OneBased}
+}
+
+func ExampleTree() {
+	genes := []gene{
+		{"A", "chr1", 100, 500, '+'},
+		{"B", "chr1", 400, 900, '-'},
+		{"C", "chr1", 1200, 1500, '+'},
+		{"D", "chr1", 2000, 2600, '-'},
+		{"E", "chr2", 100, 500, '+'},
+	}
+	tree := NewTree(genes, locate)
+	names := func(gs []gene) []string {
+		var s []string
+		for _, g := range gs {
+			s = append(s, g.name)
+		}
+		return s
+	}
+	q := Interval{"chr1", 450, 1000, '+', OneBased}
+	fmt.Println(names(tree.Overlapping(q, IgnoreStrand)))
+	fmt.Println(names(tree.Overlapping(q, SameStrand)))
+	fmt.Println(names(tree.Within(Interval{"chr1", 1, 1000, 0, OneBased}, IgnoreStrand)))
+	fmt.Println(names(tree.Containing(Interval{"chr1", 450, 460, 0, OneBased}, IgnoreStrand)))
+	up, _ := tree.Upstream(Interval{"chr1", 1000, 1100, '-', OneBased}, IgnoreStrand)
+	down, _ := tree.Downstream(Interval{"chr1", 1000, 1100, '+', OneBased}, OppositeStrand)
+	fmt.Println(up.name, down.name)
+	fmt.Println(names(tree.Nearest(Interval{"chr1", 1000, 1100, '+', OneBased}, 3, IgnoreStrand)))
+	// Output:
+	// [A B]
+	// [A]
+	// [A B]
+	// [A B]
+	// C D
+	// [C B A]
+}
+
+func ExampleInterval() {
+	gene := Interval{SeqID: "chr1", Start: 1001, End: 2000, Strand: '+'}
+	peak := Interval{SeqID: "chr1", Start: 1900, End: 2100, Coords: ZeroBased}
+	fmt.Println(gene, gene.Len(), gene.In(ZeroBased).Start)
+	fmt.Println(peak, peak.Len(), gene.Overlaps(peak))
+	common, _ := gene.Intersect(peak)
+	span, _ := gene.Union(peak)
+	fmt.Println(common, span)
+	fmt.Println(gene.Subtract(peak))
+	fmt.Println(gene.Slop(500, 0), gene.Flank(100, 100))
+	fmt.Println(Merge([]Interval{peak, gene, {SeqID: "chr1", Start: 2105, End: 2200}}, 5))
+	// Output:
+	// chr1:1001-2000(+) 1000 1000
+	// chr1:1901-2100 200 true
+	// chr1:1901-2000(+) chr1:1001-2100(+)
+	// [chr1:1001-1900(+)]
+	// chr1:501-2000(+) [chr1:901-1000(+) chr1:2001-2100(+)]
+	// [chr1:1001-2200]
+}
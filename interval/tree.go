@@ -0,0 +1,265 @@
+package interval
+
+import (
+	"sort"
+)
+
+// StrandMode controls how queries treat the strands of features
+type StrandMode int
+
+// Strand modes
+const (
+	IgnoreStrand   StrandMode = iota // match features on either strand
+	SameStrand                       // match features on the query strand
+	OppositeStrand                   // match features on the other strand
+)
+
+// matches reports whether a feature on strand s matches a query
+// on strand q
+func (m StrandMode) matches(q, s byte) bool {
+	switch m {
+	case SameStrand:
+		return s == q
+	case OppositeStrand:
+		return q == '+' && s == '-' || q == '-' && s == '+'
+	}
+	return true
+}
+
+type node[T any] struct {
+	item       T
+	start, end int
+	strand     byte
+}
+
+// seqTree indexes the features of a single sequence. Nodes are
+// sorted by start and form an implicit balanced binary tree, with
+// maxEnd holding the largest end in the subtree rooted at each node.
+type seqTree[T any] struct {
+	nodes  []node[T]
+	maxEnd []int
+	byEnd  []int // node indices sorted by end
+}
+
+func (s *seqTree[T]) build(lo, hi int) int {
+	if lo >= hi {
+		return 0
+	}
+	mid := (lo + hi) / 2
+	m := max(s.nodes[mid].end, s.build(lo, mid), s.build(mid+1, hi))
+	s.maxEnd[mid] = m
+	return m
+}
+
+// overlapping appends the nodes overlapping [start, end]
+func (s *seqTree[T]) overlapping(lo, hi, start, end int, f func(*node[T])) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	if s.maxEnd[mid] < start {
+		return
+	}
+	s.overlapping(lo, mid, start, end, f)
+	n := &s.nodes[mid]
+	if n.start <= end {
+		if n.end >= start {
+			f(n)
+		}
+		s.overlapping(mid+1, hi, start, end, f)
+	}
+}
+
+// Tree is a static index over a collection of features, partitioned
+// by sequence ID, supporting overlap, containment and nearest
+// feature queries. The features are located by a function, so any
+// type with a position can be indexed.
+type Tree[T any] struct {
+	seqs map[string]*seqTree[T]
+}
+
+// NewTree builds a Tree over items, using locate to find the
+// position of each item. Intervals may be in either coordinate
+// system.
+func NewTree[T any](items []T, locate func(T) Interval) *Tree[T] {
+	t := &Tree[T]{seqs: make(map[string]*seqTree[T])}
+	for _, item := range items {
+		loc := locate(item).In(OneBased)
+		s, ok := t.seqs[loc.SeqID]
+		if !ok {
+			s = new(seqTree[T])
+			t.seqs[loc.SeqID] = s
+		}
+		s.nodes = append(s.nodes, node[T]{item, loc.Start, loc.End, loc.Strand})
+	}
+	for _, s := range t.seqs {
+		sort.SliceStable(s.nodes, func(i, j int) bool {
+			return s.nodes[i].start < s.nodes[j].start
+		})
+		s.maxEnd = make([]int, len(s.nodes))
+		s.build(0, len(s.nodes))
+		s.byEnd = make([]int, len(s.nodes))
+		for i := range s.byEnd {
+			s.byEnd[i] = i
+		}
+		sort.SliceStable(s.byEnd, func(i, j int) bool {
+			return s.nodes[s.byEnd[i]].end < s.nodes[s.byEnd[j]].end
+		})
+	}
+	return t
+}
+
+// query calls f for every feature overlapping q that matches mode
+func (t *Tree[T]) query(q Interval, mode StrandMode, f func(*node[T])) {
+	q = q.In(OneBased)
+	s, ok := t.seqs[q.SeqID]
+	if !ok {
+		return
+	}
+	s.overlapping(0, len(s.nodes), q.Start, q.End, func(n *node[T]) {
+		if mode.matches(q.Strand, n.strand) {
+			f(n)
+		}
+	})
+}
+
+// Overlapping returns the features that overlap q by at least one
+// base, ordered by start
+func (t *Tree[T]) Overlapping(q Interval, mode StrandMode) []T {
+	var items []T
+	t.query(q, mode, func(n *node[T]) { items = append(items, n.item) })
+	return items
+}
+
+// Containing returns the features that entirely contain q
+func (t *Tree[T]) Containing(q Interval, mode StrandMode) []T {
+	q = q.In(OneBased)
+	var items []T
+	t.query(q, mode, func(n *node[T]) {
+		if n.start <= q.Start && n.end >= q.End {
+			items = append(items, n.item)
+		}
+	})
+	return items
+}
+
+// Within returns the features that lie entirely within q
+func (t *Tree[T]) Within(q Interval, mode StrandMode) []T {
+	q = q.In(OneBased)
+	var items []T
+	t.query(q, mode, func(n *node[T]) {
+		if n.start >= q.Start && n.end <= q.End {
+			items = append(items, n.item)
+		}
+	})
+	return items
+}
+
+// right returns the index of the first node starting after q,
+// in start order
+func (s *seqTree[T]) right(q Interval) int {
+	return sort.Search(len(s.nodes), func(i int) bool {
+		return s.nodes[i].start > q.End
+	})
+}
+
+// left returns the position in byEnd of the last node ending
+// before q, or -1
+func (s *seqTree[T]) left(q Interval) int {
+	return sort.Search(len(s.byEnd), func(i int) bool {
+		return s.nodes[s.byEnd[i]].end >= q.Start
+	}) - 1
+}
+
+// Upstream returns the nearest feature that ends before q starts,
+// in the direction of q's strand: features at lower coordinates
+// for '+' or unstranded queries and at higher ones for '-'.
+func (t *Tree[T]) Upstream(q Interval, mode StrandMode) (T, bool) {
+	if q.Strand == '-' {
+		return t.after(q, mode)
+	}
+	return t.before(q, mode)
+}
+
+// Downstream returns the nearest feature that starts after q ends,
+// in the direction of q's strand
+func (t *Tree[T]) Downstream(q Interval, mode StrandMode) (T, bool) {
+	if q.Strand == '-' {
+		return t.before(q, mode)
+	}
+	return t.after(q, mode)
+}
+
+// before returns the non-overlapping feature to the left of q with
+// the largest end
+func (t *Tree[T]) before(q Interval, mode StrandMode) (T, bool) {
+	q = q.In(OneBased)
+	var zero T
+	s, ok := t.seqs[q.SeqID]
+	if !ok {
+		return zero, false
+	}
+	for i := s.left(q); i >= 0; i-- {
+		n := &s.nodes[s.byEnd[i]]
+		if mode.matches(q.Strand, n.strand) {
+			return n.item, true
+		}
+	}
+	return zero, false
+}
+
+// after returns the non-overlapping feature to the right of q with
+// the smallest start
+func (t *Tree[T]) after(q Interval, mode StrandMode) (T, bool) {
+	q = q.In(OneBased)
+	var zero T
+	s, ok := t.seqs[q.SeqID]
+	if !ok {
+		return zero, false
+	}
+	for i := s.right(q); i < len(s.nodes); i++ {
+		n := &s.nodes[i]
+		if mode.matches(q.Strand, n.strand) {
+			return n.item, true
+		}
+	}
+	return zero, false
+}
+
+// Nearest returns up to k features closest to q, ordered by
+// distance. Overlapping features have distance 0; otherwise the
+// distance is the number of bases between q and the feature plus
+// one, so adjacent features have distance 1.
+func (t *Tree[T]) Nearest(q Interval, k int, mode StrandMode) []T {
+	q = q.In(OneBased)
+	var items []T
+	t.query(q, mode, func(n *node[T]) {
+		if len(items) < k {
+			items = append(items, n.item)
+		}
+	})
+	s, ok := t.seqs[q.SeqID]
+	if !ok {
+		return items
+	}
+	i, j := s.right(q), s.left(q)
+	for len(items) < k {
+		for i < len(s.nodes) && !mode.matches(q.Strand, s.nodes[i].strand) {
+			i++
+		}
+		for j >= 0 && !mode.matches(q.Strand, s.nodes[s.byEnd[j]].strand) {
+			j--
+		}
+		switch {
+		case i < len(s.nodes) && (j < 0 || s.nodes[i].start-q.End <= q.Start-s.nodes[s.byEnd[j]].end):
+			items = append(items, s.nodes[i].item)
+			i++
+		case j >= 0:
+			items = append(items, s.nodes[s.byEnd[j]].item)
+			j--
+		default:
+			return items
+		}
+	}
+	return items
+}
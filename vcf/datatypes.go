@@ -2,6 +2,7 @@ package vcf
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -97,8 +98,26 @@ func ParseStringVector(str string) (StringVector, error) {
 	return s, nil
 }
 
+// MissingInteger represents a missing ('.') Integer value. As in
+// htslib, it is the smallest 32-bit integer, which the VCF
+// specification reserves, so that value cannot be read from a file.
+const MissingInteger Integer = math.MinInt32
+
+// IsMissing reports whether i is the missing value
+func (i Integer) IsMissing() bool {
+	return i == MissingInteger
+}
+
+// ParseInteger parses an Integer, returning MissingInteger for '.'
+// and an error for the reserved value MissingInteger itself
 func ParseInteger(s string) (Integer, error) {
+	if s == "." {
+		return MissingInteger, nil
+	}
 	val, err := strconv.ParseInt(s, 10, 64)
+	if err == nil && Integer(val) == MissingInteger {
+		return 0, fmt.Errorf("integer %s is reserved for missing values", s)
+	}
 	return Integer(val), err
 }
 
@@ -115,7 +134,16 @@ func ParseIntegerVector(s string) (IntegerVector, error) {
 	return vs, nil
 }
 
+// IsMissing reports whether f is the missing value, NaN
+func (f Float) IsMissing() bool {
+	return math.IsNaN(float64(f))
+}
+
+// ParseFloat parses a Float, returning NaN for '.'
 func ParseFloat(s string) (Float, error) {
+	if s == "." {
+		return Float(math.NaN()), nil
+	}
 	val, err := strconv.ParseFloat(s, 64)
 	return Float(val), err
 }
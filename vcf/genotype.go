@@ -0,0 +1,229 @@
+package vcf
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MissingAllele is the allele index of a missing ('.') allele
+const MissingAllele = -1
+
+// Genotype is a parsed GT field. Alleles holds the allele index of
+// each chromosome copy: 0 for the reference, 1 for the first
+// alternate allele and so on, or MissingAllele. Phased[i] reports
+// whether allele i is phased, that is, preceded by '|' rather than
+// '/'. Following VCF 4.4, the phasing of the first allele may be
+// given by a leading '|' or '/'; if it is not, the first allele is
+// phased when all the others are, so haploid calls are phased.
+type Genotype struct {
+	Alleles []int
+	Phased  []bool
+}
+
+// ParseGenotype parses a GT field of any ploidy, such as "0/1",
+// "1|0", "0/1/2", "1" or "./."
+func ParseGenotype(s string) (Genotype, error) {
+	var g Genotype
+	if len(s) == 0 {
+		return g, fmt.Errorf("vcf: empty genotype")
+	}
+	var leading byte
+	if s[0] == '|' || s[0] == '/' {
+		leading = s[0]
+		s = s[1:]
+	}
+	sep := leading
+	for {
+		token := s
+		end := strings.IndexAny(s, "/|")
+		if end >= 0 {
+			token = s[:end]
+		}
+		allele := MissingAllele
+		if token != "." {
+			a, err := strconv.Atoi(token)
+			if err != nil || a < 0 {
+				return Genotype{}, fmt.Errorf("vcf: invalid allele %q in genotype", token)
+			}
+			allele = a
+		}
+		g.Alleles = append(g.Alleles, allele)
+		g.Phased = append(g.Phased, sep == '|')
+		if end < 0 {
+			break
+		}
+		sep, s = s[end], s[end+1:]
+	}
+	if leading == 0 {
+		g.Phased[0] = g.impliedPhase()
+	}
+	return g, nil
+}
+
+// impliedPhase returns the phasing of the first allele when no
+// leading indicator is given
+func (g Genotype) impliedPhase() bool {
+	for i := 1; i < len(g.Alleles); i++ {
+		if !g.phased(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (g Genotype) phased(i int) bool {
+	return i < len(g.Phased) && g.Phased[i]
+}
+
+// String returns the genotype as a GT field. A leading phasing
+// indicator is written only if it differs from the implied one.
+func (g Genotype) String() string {
+	var b strings.Builder
+	for i, a := range g.Alleles {
+		if i > 0 || g.phased(0) != g.impliedPhase() {
+			if g.phased(i) {
+				b.WriteByte('|')
+			} else {
+				b.WriteByte('/')
+			}
+		}
+		if a == MissingAllele {
+			b.WriteByte('.')
+		} else {
+			b.WriteString(strconv.Itoa(a))
+		}
+	}
+	return b.String()
+}
+
+// Ploidy returns the number of alleles in the genotype
+func (g Genotype) Ploidy() int {
+	return len(g.Alleles)
+}
+
+// IsPhased reports whether every allele of the genotype is phased
+func (g Genotype) IsPhased() bool {
+	for i := range g.Alleles {
+		if !g.phased(i) {
+			return false
+		}
+	}
+	return len(g.Alleles) > 0
+}
+
+// IsMissing reports whether every allele is missing
+func (g Genotype) IsMissing() bool {
+	for _, a := range g.Alleles {
+		if a != MissingAllele {
+			return false
+		}
+	}
+	return true
+}
+
+// HasMissing reports whether any allele is missing
+func (g Genotype) HasMissing() bool {
+	for _, a := range g.Alleles {
+		if a == MissingAllele {
+			return true
+		}
+	}
+	return false
+}
+
+// isHom reports whether every allele is called and equal to the
+// first, which satisfies pred
+func (g Genotype) isHom(pred func(int) bool) bool {
+	if len(g.Alleles) == 0 || g.HasMissing() || !pred(g.Alleles[0]) {
+		return false
+	}
+	for _, a := range g.Alleles[1:] {
+		if a != g.Alleles[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsHomRef reports whether every allele is the reference. Calls
+// with missing alleles are neither homozygous nor heterozygous.
+func (g Genotype) IsHomRef() bool {
+	return g.isHom(func(a int) bool { return a == 0 })
+}
+
+// IsHomAlt reports whether every allele is the same alternate allele
+func (g Genotype) IsHomAlt() bool {
+	return g.isHom(func(a int) bool { return a > 0 })
+}
+
+// IsHet reports whether the genotype has at least two different
+// alleles and none missing
+func (g Genotype) IsHet() bool {
+	return len(g.Alleles) > 1 && !g.HasMissing() &&
+		!g.isHom(func(int) bool { return true })
+}
+
+// AltDosage returns the number of called alleles that are not the
+// reference
+func (g Genotype) AltDosage() int {
+	var n int
+	for _, a := range g.Alleles {
+		if a > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// checkAlleles returns an error if g refers to an allele beyond
+// the alternate alleles of r
+func (r *Record) checkAlleles(g Genotype) error {
+	n := r.AltCount()
+	for _, a := range g.Alleles {
+		if a > n {
+			return fmt.Errorf("vcf: genotype %s refers to allele %d of %d", g, a, n)
+		}
+	}
+	return nil
+}
+
+// Genotype returns the parsed GT field of the named sample. A
+// sample whose GT field is omitted is returned as missing.
+func (r *Record) Genotype(name string) (Genotype, error) {
+	gt, ok := r.SampleValue(name, "GT")
+	if !ok {
+		return Genotype{}, fmt.Errorf("vcf: GT for sample %s: %w", name, ErrFieldNotFound)
+	}
+	g, err := ParseGenotype(gt)
+	if err != nil {
+		return g, err
+	}
+	return g, r.checkAlleles(g)
+}
+
+// SampleGenotypes returns the parsed GT field of every sample of
+// the record, in column order. It does not require a header.
+func (r *Record) SampleGenotypes() ([]Genotype, error) {
+	j := slices.Index(r.Format, "GT")
+	if j < 0 {
+		return nil, fmt.Errorf("vcf: GT: %w", ErrFieldNotFound)
+	}
+	genotypes := make([]Genotype, len(r.Genotypes))
+	for i, fields := range r.Genotypes {
+		gt := "."
+		if j < len(fields) {
+			gt = fields[j]
+		}
+		g, err := ParseGenotype(gt)
+		if err == nil {
+			err = r.checkAlleles(g)
+		}
+		if err != nil {
+			return nil, err
+		}
+		genotypes[i] = g
+	}
+	return genotypes, nil
+}
@@ -0,0 +1,152 @@
+package vcf
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Header holds the meta-information lines of a VCF file and the
+// sample names from its #CHROM line. INFO and FORMAT definitions
+// are indexed by ID; all other lines, apart from fileformat, are
+// kept in Metadata in the order read.
+type Header struct {
+	Fileformat string
+	Metadata   []*Metadata
+	Info       map[string]*Metadata
+	Format     map[string]*Metadata
+	Samples    []string
+
+	order       []*Metadata // lines added with Add, in order
+	sampleIndex map[string]int
+}
+
+// NewHeader initializes a vcf.Header struct
+func NewHeader() *Header {
+	var h Header
+	h.Info = make(map[string]*Metadata)
+	h.Format = make(map[string]*Metadata)
+	return &h
+}
+
+// Add adds a meta-information line to the header, as if read at
+// the end of the existing lines
+func (h *Header) Add(meta *Metadata) {
+	switch meta.Class {
+	case "fileformat":
+		h.Fileformat = meta.Value
+		return
+	case "INFO":
+		if meta.ID == "" {
+			return
+		}
+		h.Info[meta.ID] = meta
+	case "FORMAT":
+		if meta.ID == "" {
+			return
+		}
+		h.Format[meta.ID] = meta
+	default:
+		h.Metadata = append(h.Metadata, meta)
+	}
+	h.order = append(h.order, meta)
+}
+
+// Lines returns the meta-information lines of the header other
+// than fileformat, in the order they were added. Lines placed
+// directly in Metadata follow, then INFO and FORMAT definitions
+// placed directly in their maps, sorted by ID.
+func (h *Header) Lines() []*Metadata {
+	var lines []*Metadata
+	seen := make(map[*Metadata]bool)
+	for _, meta := range h.order {
+		current := slices.Contains(h.Metadata, meta)
+		switch meta.Class {
+		case "INFO":
+			current = h.Info[meta.ID] == meta
+		case "FORMAT":
+			current = h.Format[meta.ID] == meta
+		}
+		if current {
+			lines = append(lines, meta)
+			seen[meta] = true
+		}
+	}
+	for _, meta := range h.Metadata {
+		if !seen[meta] {
+			lines = append(lines, meta)
+		}
+	}
+	for _, defs := range []map[string]*Metadata{h.Info, h.Format} {
+		var extra []*Metadata
+		for _, meta := range defs {
+			if !seen[meta] {
+				extra = append(extra, meta)
+			}
+		}
+		sort.Slice(extra, func(i, j int) bool { return extra[i].ID < extra[j].ID })
+		lines = append(lines, extra...)
+	}
+	return lines
+}
+
+// infoOrder returns the INFO IDs of the header, in header order
+func (h *Header) infoOrder() []string {
+	var ids []string
+	for _, meta := range h.Lines() {
+		if meta.Class == "INFO" && h.Info[meta.ID] == meta {
+			ids = append(ids, meta.ID)
+		}
+	}
+	return ids
+}
+
+// fixedColumns are the required columns of the #CHROM line
+var fixedColumns = []string{"#CHROM", "POS", "ID", "REF", "ALT", "QUAL", "FILTER", "INFO"}
+
+// parseHeaderLine checks the columns of the #CHROM line and sets
+// the sample names
+func (h *Header) parseHeaderLine(line string) error {
+	cols := strings.Split(line, "\t")
+	if len(cols) < len(fixedColumns) {
+		return fmt.Errorf("%w: %d columns", ErrHeaderLine, len(cols))
+	}
+	for i, name := range fixedColumns {
+		if cols[i] != name {
+			return fmt.Errorf("%w: column %d is %q, expected %q", ErrHeaderLine, i+1, cols[i], name)
+		}
+	}
+	h.Samples, h.sampleIndex = nil, nil
+	if len(cols) == len(fixedColumns) {
+		return nil
+	}
+	if cols[8] != "FORMAT" {
+		return fmt.Errorf("%w: column 9 is %q, expected \"FORMAT\"", ErrHeaderLine, cols[8])
+	}
+	samples := cols[9:]
+	index := make(map[string]int, len(samples))
+	for i, name := range samples {
+		if len(name) == 0 {
+			return fmt.Errorf("%w: empty sample name in column %d", ErrHeaderLine, i+10)
+		}
+		if _, ok := index[name]; ok {
+			return fmt.Errorf("%w %q", ErrDuplicateSample, name)
+		}
+		index[name] = i
+	}
+	h.Samples, h.sampleIndex = samples, index
+	return nil
+}
+
+// SampleIndex returns the position of the named sample in Samples,
+// and so in the Genotypes of each record, or -1 if there is no
+// such sample. Lookups use an index built when the #CHROM line is
+// read, falling back to a search of Samples if there is no index
+// or Samples has been changed since.
+func (h *Header) SampleIndex(name string) int {
+	if i, ok := h.sampleIndex[name]; ok && i < len(h.Samples) && h.Samples[i] == name {
+		return i
+	}
+	return slices.Index(h.Samples, name)
+}
@@ -2,6 +2,8 @@ package vcf
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -16,6 +18,9 @@ type Metadata struct {
 	Source      string
 	Version     string
 	OtherFields map[string]string
+
+	keys    []string // keys of all fields, in the order read
+	hasType bool     // a Type field was read
 }
 
 // NewMetadata is a constructor for the metafield struct
@@ -55,14 +60,13 @@ func ParseMetadata(s string) (*Metadata, error) {
 		return result, nil
 	}
 
-	fieldstr := strings.Trim(classval[1], "<>")
-	matches := keyvalRe.FindAllStringSubmatch(fieldstr, -1)
-
-	for _, match := range matches {
-		// note that match[0] is the entire match
-		// while match[1] and match[2] are the desired subgroups
-		key := match[1]
-		value := match[2]
+	fields, err := parseFields(val[1 : len(val)-1])
+	if err != nil {
+		return result, err
+	}
+	var typ string
+	for _, field := range fields {
+		key, value := field[0], field[1]
 		switch key {
 		case "ID":
 			result.ID = value
@@ -75,37 +79,158 @@ func ParseMetadata(s string) (*Metadata, error) {
 		case "Version":
 			result.Version = value
 		case "Type":
-			switch value {
-			case "Integer":
-				if result.Number == "1" {
-					result.Type = IntegerType
-				} else {
-					result.Type = IntegerVectorType
-				}
-			case "Float":
-				if result.Number == "1" {
-					result.Type = FloatType
-				} else {
-					result.Type = FloatVectorType
-				}
-			case "String":
-				if result.Number == "1" {
-					result.Type = StringType
-				} else {
-					result.Type = StringVectorType
-				}
-			case "Character":
-				if result.Number == "1" {
-					result.Type = CharacterType
-				} else {
-					result.Type = CharacterVectorType
-				}
-			case "Flag":
-				result.Type = FlagType
-			}
+			result.hasType = true
+			typ = value
 		default:
 			result.OtherFields[key] = value
 		}
+		result.keys = append(result.keys, key)
 	}
+	// Number may follow Type, so the Type is set once both are known
+	result.Type = datatypeOf(typ, result.Number)
 	return result, nil
 }
+
+// datatypeOf returns the DatatypeType for a Type and Number; types
+// other than Flag are vectors unless Number is 1
+func datatypeOf(typ, number string) DatatypeType {
+	scalar := number == "1"
+	switch typ {
+	case "Integer":
+		if scalar {
+			return IntegerType
+		}
+		return IntegerVectorType
+	case "Float":
+		if scalar {
+			return FloatType
+		}
+		return FloatVectorType
+	case "Character":
+		if scalar {
+			return CharacterType
+		}
+		return CharacterVectorType
+	case "Flag":
+		return FlagType
+	case "String":
+		if !scalar {
+			return StringVectorType
+		}
+	}
+	return StringType
+}
+
+// parseFields splits the contents of a structured meta-information
+// line into key=value pairs. Quoted values may contain commas and
+// backslash-escaped quotes and backslashes; they are returned
+// unquoted. Bracketed lists, as in Values=[a, b], may also contain
+// commas and are returned with their brackets.
+func parseFields(s string) ([][2]string, error) {
+	var fields [][2]string
+	for len(s) > 0 {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return fields, fmt.Errorf("invalid metadata field %q", s)
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+		var value strings.Builder
+		if strings.HasPrefix(s, `"`) {
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return fields, fmt.Errorf("unterminated quoted value for %s", key)
+			}
+			s = s[i+1:]
+		} else if strings.HasPrefix(s, "[") {
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return fields, fmt.Errorf("unterminated list value for %s", key)
+			}
+			value.WriteString(s[:end+1])
+			s = s[end+1:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value.WriteString(s[:end])
+			s = s[end:]
+		}
+		fields = append(fields, [2]string{key, value.String()})
+		if len(s) > 0 && s[0] != ',' {
+			return fields, fmt.Errorf("invalid metadata field %s", key)
+		}
+		s = strings.TrimPrefix(s, ",")
+	}
+	return fields, nil
+}
+
+// quote returns s as a quoted metadata value
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Line returns the metadata as a meta-information line, without a
+// line ending. Structured lines are rebuilt from the fields of m,
+// keeping the order in which they were read. Fields that were not
+// read follow: ID, Number, Type, Description, Source and Version,
+// then OtherFields sorted by key.
+func (m *Metadata) Line() string {
+	if m.ID == "" && !strings.HasPrefix(m.Value, "<") {
+		return "##" + m.Class + "=" + m.Value
+	}
+	required := m.Class == "INFO" || m.Class == "FORMAT"
+	// field returns the formatted value of a field, if it is set
+	field := func(key string) (string, bool) {
+		switch key {
+		case "ID":
+			return m.ID, m.ID != ""
+		case "Number":
+			return m.Number, m.Number != ""
+		case "Type":
+			// StringType is the zero Type, so it is written only if
+			// read or required
+			return m.Type.String(), m.hasType || m.Type != StringType || required
+		case "Description":
+			return quote(m.Description), m.Description != "" || required
+		case "Source":
+			return quote(m.Source), m.Source != ""
+		case "Version":
+			return quote(m.Version), m.Version != ""
+		}
+		value, ok := m.OtherFields[key]
+		list := strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]")
+		if !list && strings.ContainsAny(value, ",\"<>= ") {
+			value = quote(value)
+		}
+		return value, ok
+	}
+	keys := append([]string(nil), m.keys...)
+	for _, key := range []string{"ID", "Number", "Type", "Description", "Source", "Version"} {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	var extra []string
+	for key := range m.OtherFields {
+		if !slices.Contains(keys, key) {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	var fields []string
+	for _, key := range append(keys, extra...) {
+		if value, ok := field(key); ok {
+			fields = append(fields, key+"="+value)
+		}
+	}
+	return "##" + m.Class + "=<" + strings.Join(fields, ",") + ">"
+}
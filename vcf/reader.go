@@ -0,0 +1,192 @@
+package vcf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// Errors describing malformed VCF input, wrapped in a ParseError
+var (
+	ErrNoHeaderLine    = errors.New("missing #CHROM header line")
+	ErrHeaderLine      = errors.New("invalid #CHROM header line")
+	ErrDuplicateSample = errors.New("duplicate sample name")
+	ErrSampleCount     = errors.New("number of sample columns does not match header")
+)
+
+// ParseError reports the location and reason of a VCF parsing
+// failure. Line numbers are 1-based.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("vcf: line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads a VCF file one record at a time. The header is
+// parsed when the Reader is created, and records are then read
+// with Next. The #CHROM line is validated, and each record must
+// have a genotype column for every sample it names. Lines may be
+// of any length, so files with many thousands of samples can be
+// read without holding more than one record in memory.
+type Reader struct {
+	r      *bufio.Reader
+	header *Header
+	text   string // the first record line, read with the header
+	rec    *Record
+	err    error
+	line   int
+}
+
+// NewReader returns a new Reader that reads from r, having read
+// the header
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReader(r), header: NewHeader()}
+	if err := reader.readHeader(); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// readLine returns the next line of input, without its line ending
+func (r *Reader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err == nil {
+		r.line++
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// readHeader reads the meta-information lines and the #CHROM line,
+// stopping at the first record. A missing #CHROM line is an error
+// unless the file has no records.
+func (r *Reader) readHeader() error {
+	var headerLine bool
+	for {
+		line, err := r.readLine()
+		if err == io.EOF {
+			r.err = err
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(strings.TrimSpace(line)) == 0:
+			continue
+		case strings.HasPrefix(line, "#CHROM"):
+			if err := r.header.parseHeaderLine(line); err != nil {
+				return &ParseError{Line: r.line, Err: err}
+			}
+			headerLine = true
+			continue
+		case strings.HasPrefix(line, "##"):
+			meta, err := ParseMetadata(line)
+			if err != nil {
+				return &ParseError{Line: r.line, Err: err}
+			}
+			r.header.Add(meta)
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+		if !headerLine {
+			return &ParseError{Line: r.line, Err: ErrNoHeaderLine}
+		}
+		r.text = line
+		return nil
+	}
+}
+
+// Header returns the header of the file
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Next advances the Reader to the next record, which is then
+// available through Record. It returns false when there are no
+// more records, either because the end of the input was reached or
+// an error occurred; Err distinguishes the two cases.
+func (r *Reader) Next() bool {
+	r.rec = nil
+	if r.err != nil {
+		return false
+	}
+	for {
+		line := r.text
+		r.text = ""
+		if len(line) == 0 {
+			var err error
+			if line, err = r.readLine(); err != nil {
+				r.err = err
+				return false
+			}
+		}
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rec, err := ParseRecord(line)
+		if err == nil && len(rec.Genotypes) != len(r.header.Samples) {
+			err = fmt.Errorf("%w: %d columns, %d samples",
+				ErrSampleCount, len(rec.Genotypes), len(r.header.Samples))
+		}
+		if err != nil {
+			r.err = &ParseError{Line: r.line, Err: err}
+			return false
+		}
+		rec.header = r.header
+		r.rec = rec
+		return true
+	}
+}
+
+// Record returns the most recent record read by a call to Next
+func (r *Reader) Record() *Record {
+	return r.rec
+}
+
+// Err returns the first non-EOF error encountered by the Reader
+func (r *Reader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// ReadAll reads all the remaining records from the input
+func (r *Reader) ReadAll() ([]*Record, error) {
+	var records []*Record
+	for r.Next() {
+		records = append(records, r.Record())
+	}
+	return records, r.Err()
+}
+
+// Records returns an iterator over the remaining records in the
+// input. Iteration stops after the first error, which is yielded
+// with a nil Record.
+func (r *Reader) Records() iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		for r.Next() {
+			if !yield(r.Record(), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package vcf
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+
+	"github.com/pmagwene/biofiles/interval"
 )
 
 // Record is a representation of the fields of a single VCF record
@@ -20,6 +23,8 @@ type Record struct {
 	Format    []string
 	Genotypes [][]string
 	HasQual   bool
+
+	header *Header // header of the file the record was read from
 }
 
 // NewRecord constructs a vcf.Record
@@ -63,7 +68,60 @@ func ParseRecord(s string) (*Record, error) {
 	if len(parts) > 9 {
 		r.parseGenotypes(parts[9:])
 	}
-	return r, err
+	return r, nil
+}
+
+// Interval returns the reference bases spanned by the record, in
+// 1-based closed coordinates. The end is taken from the END info
+// field if present, as for structural variants, and otherwise from
+// the length of the reference allele.
+func (r *Record) Interval() interval.Interval {
+	end := r.Pos + max(len(r.Ref), 1) - 1
+	if v, ok := r.Info["END"]; ok {
+		if e, err := strconv.Atoi(v); err == nil {
+			end = e
+		}
+	}
+	return interval.Interval{SeqID: r.Chrom, Start: r.Pos, End: end}
+}
+
+// Header returns the header of the file the record was read from,
+// or nil for a record not read by a Reader
+func (r *Record) Header() *Header {
+	return r.header
+}
+
+// Sample returns the genotype fields of the named sample, in the
+// order given by Format. It returns false if the record has no
+// header or the header has no such sample.
+func (r *Record) Sample(name string) ([]string, bool) {
+	if r.header == nil {
+		return nil, false
+	}
+	i := r.header.SampleIndex(name)
+	if i < 0 || i >= len(r.Genotypes) {
+		return nil, false
+	}
+	return r.Genotypes[i], true
+}
+
+// SampleValue returns the value of the FORMAT field key for the
+// named sample. Trailing fields omitted from the sample column are
+// returned as the missing value ".". It returns false if there is
+// no such sample or the record has no such field.
+func (r *Record) SampleValue(name, key string) (string, bool) {
+	fields, ok := r.Sample(name)
+	if !ok {
+		return "", false
+	}
+	j := slices.Index(r.Format, key)
+	if j < 0 {
+		return "", false
+	}
+	if j >= len(fields) {
+		return ".", true
+	}
+	return fields[j], true
 }
 
 func (r *Record) parseGenotypes(fields []string) {
@@ -76,6 +134,9 @@ func (r *Record) parseGenotypes(fields []string) {
 func (r *Record) parseInfo(s string) {
 
 	for _, field := range strings.Split(s, ";") {
+		if len(field) == 0 || field == "." { // Missing INFO
+			continue
+		}
 		if !strings.Contains(field, "=") { // Flag field
 			r.Info[field] = field
 			continue
@@ -0,0 +1,168 @@
+package vcf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Errors returned by the typed INFO and FORMAT getters
+var (
+	ErrNoHeader       = errors.New("record has no header")
+	ErrUndefinedField = errors.New("field not defined in header")
+	ErrFieldNotFound  = errors.New("field not present in record")
+	ErrCardinality    = errors.New("wrong number of values")
+	ErrTypeMismatch   = errors.New("field has a different type")
+)
+
+// AltCount returns the number of alternate alleles of the record
+func (r *Record) AltCount() int {
+	if len(r.Alt) == 0 || r.Alt == "." {
+		return 0
+	}
+	return strings.Count(r.Alt, ",") + 1
+}
+
+// genotypeCount returns the number of possible unordered genotypes
+// of the given ploidy with n alleles
+func genotypeCount(n, ploidy int) int {
+	// binomial coefficient (n+ploidy-1 choose ploidy)
+	count := 1
+	for i := 1; i <= ploidy; i++ {
+		count = count * (n + i - 1) / i
+	}
+	return count
+}
+
+// expectedCount returns the number of values required by a Number
+// declaration, or -1 if it does not fix the number
+func (r *Record) expectedCount(number string, ploidy int) int {
+	switch number {
+	case "A":
+		return r.AltCount()
+	case "R":
+		return r.AltCount() + 1
+	case "G":
+		return genotypeCount(r.AltCount()+1, ploidy)
+	}
+	if n, err := strconv.Atoi(number); err == nil {
+		return n
+	}
+	return -1
+}
+
+// parseField parses the value s of a field according to its
+// definition, checking the number of values of vector types
+func (r *Record) parseField(meta *Metadata, s string, ploidy int) (Datatype, error) {
+	value, err := ParseDatatype(meta.Type, s)
+	if err != nil {
+		return nil, fmt.Errorf("vcf: %s %s: %w", meta.Class, meta.ID, err)
+	}
+	if s == "." {
+		return value, nil
+	}
+	var count int
+	switch v := value.(type) {
+	case IntegerVector:
+		count = len(v)
+	case FloatVector:
+		count = len(v)
+	case StringVector:
+		count = len(v)
+	case CharacterVector:
+		count = len(v)
+	default:
+		return value, nil
+	}
+	if n := r.expectedCount(meta.Number, ploidy); n >= 0 && count != n {
+		return value, fmt.Errorf("vcf: %s %s: %w: %d, expected %d (Number=%s)",
+			meta.Class, meta.ID, ErrCardinality, count, n, meta.Number)
+	}
+	return value, nil
+}
+
+// InfoDatatype returns the value of the INFO field key, parsed
+// according to its definition in the header: an Integer, Float,
+// String, Character or Flag, or a vector of one of these. Missing
+// values ('.') are returned as MissingInteger or NaN for numeric
+// types, and the number of values of a vector is checked against
+// the declared Number, with Number=G taken as diploid. An absent
+// Flag is returned as false; other absent fields give an error
+// wrapping ErrFieldNotFound.
+func (r *Record) InfoDatatype(key string) (Datatype, error) {
+	if r.header == nil {
+		return nil, ErrNoHeader
+	}
+	meta, ok := r.header.Info[key]
+	if !ok {
+		return nil, fmt.Errorf("vcf: INFO %s: %w", key, ErrUndefinedField)
+	}
+	s, ok := r.Info[key]
+	if !ok {
+		if meta.Type == FlagType {
+			return Flag(false), nil
+		}
+		return nil, fmt.Errorf("vcf: INFO %s: %w", key, ErrFieldNotFound)
+	}
+	return r.parseField(meta, s, 2)
+}
+
+// ploidy returns the number of alleles in the GT field of a
+// sample, or 2 if it is absent or unknown
+func ploidy(gt string, ok bool) int {
+	if !ok || gt == "." {
+		return 2
+	}
+	g, err := ParseGenotype(gt)
+	if err != nil {
+		return 2
+	}
+	return g.Ploidy()
+}
+
+// SampleDatatype returns the value of the FORMAT field key for the
+// named sample, parsed according to its definition in the header
+// as for InfoDatatype. The ploidy for Number=G fields is taken
+// from the sample's GT field.
+func (r *Record) SampleDatatype(name, key string) (Datatype, error) {
+	if r.header == nil {
+		return nil, ErrNoHeader
+	}
+	meta, ok := r.header.Format[key]
+	if !ok {
+		return nil, fmt.Errorf("vcf: FORMAT %s: %w", key, ErrUndefinedField)
+	}
+	s, ok := r.SampleValue(name, key)
+	if !ok {
+		return nil, fmt.Errorf("vcf: FORMAT %s for sample %s: %w", key, name, ErrFieldNotFound)
+	}
+	return r.parseField(meta, s, ploidy(r.SampleValue(name, "GT")))
+}
+
+// as converts a Datatype to the requested type
+func as[T Datatype](value Datatype, err error) (T, error) {
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+	v, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("vcf: %w: %T, not %T", ErrTypeMismatch, value, zero)
+	}
+	return v, nil
+}
+
+// GetInfo returns the value of an INFO field as the Datatype T,
+// which must match the header definition, e.g.
+//
+//	af, err := vcf.GetInfo[vcf.FloatVector](rec, "AF")
+func GetInfo[T Datatype](r *Record, key string) (T, error) {
+	return as[T](r.InfoDatatype(key))
+}
+
+// GetSample returns the value of a FORMAT field of the named
+// sample as the Datatype T, which must match the header definition
+func GetSample[T Datatype](r *Record, name, key string) (T, error) {
+	return as[T](r.SampleDatatype(name, key))
+}
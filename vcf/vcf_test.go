@@ -2,6 +2,7 @@ package vcf
 
 import (
 	"fmt"
+	"os"
 	"strings"
 )
 
@@ -12,11 +13,219 @@ var vcfstring string = `
 ##FORMAT=<ID=PL,Number=G,Type=Float,Description="Phred-scaled Genotype Likelihoods">
 #CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	SAMP001	SAMP002
 20	1291018	rs11449	G	A	.	PASS	.	GT	0/0	0/1
-20	2300608 rs84825 C	T	.	PASS	.	GT:GP	0/1:.	0/1:0.03,0.97,0
-20	2301308 rs84823 T	G	.	PASS	.	GT:PL	./.:.	1/1:10,5,0
+20	2300608	rs84825	C	T	.	PASS	.	GT:GP	0/1:.	0/1:0.03,0.97,0
+20	2301308	rs84823	T	G	.	PASS	.	GT:PL	./.:.	1/1:10,5,0
 `
 
 func ExampleParseFile() {
-	f, _ := ParseFile(strings.NewReader(vcfstring))
-	fmt.Println(f)
+	f, err := ParseFile(strings.NewReader(vcfstring))
+	fmt.Println(f.Fileformat, f.Samples, len(f.Records), err)
+	for _, rec := range f.Records {
+		fmt.Println(rec.Chrom, rec.Pos, rec.ID, rec.Ref, rec.Alt, rec.HasQual, rec.Filter, rec.Genotypes)
+	}
+	// Output:
+	// VCFv4.2 [SAMP001 SAMP002] 3 <nil>
+	// 20 1291018 rs11449 G A false PASS [[0/0] [0/1]]
+	// 20 2300608 rs84825 C T false PASS [[0/1 .] [0/1 0.03,0.97,0]]
+	// 20 2301308 rs84823 T G false PASS [[./. .] [1/1 10,5,0]]
+}
+
+func ExampleParseRecord() {
+	rec, err := ParseRecord("20\t14370\trs6054257\tG\tA\t.\tPASS\tDP=14")
+	fmt.Println(rec.ID, rec.HasQual, rec.Qual, err)
+	rec, err = ParseRecord("20\t17330\t.\tT\tA\t3\tq10\t.")
+	fmt.Println(rec.ID, rec.HasQual, rec.Qual, err)
+	// Output:
+	// rs6054257 false 0 <nil>
+	// . true 3 <nil>
+}
+
+func ExampleReader() {
+	reader, err := NewReader(strings.NewReader(vcfstring))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	header := reader.Header()
+	fmt.Println(header.Fileformat, len(header.Format))
+	for reader.Next() {
+		rec := reader.Record()
+		fmt.Println(rec.Chrom, rec.Pos, rec.ID, rec.Ref, rec.Alt, rec.Format, rec.Genotypes)
+	}
+	if err := reader.Err(); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// VCFv4.2 3
+	// 20 1291018 rs11449 G A [GT] [[0/0] [0/1]]
+	// 20 2300608 rs84825 C T [GT GP] [[0/1 .] [0/1 0.03,0.97,0]]
+	// 20 2301308 rs84823 T G [GT PL] [[./. .] [1/1 10,5,0]]
+}
+
+func ExampleWriteFile() {
+	var vcfFile = `##fileformat=VCFv4.3
+##contig=<ID=20,length=64444167,assembly="GRCh38, primary">
+##INFO=<ID=DP,Number=1,Type=Integer,Description="Total Depth">
+##INFO=<ID=DB,Number=0,Type=Flag,Description="dbSNP membership, build 129">
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele \"Frequency\"">
+##FILTER=<ID=q10,Description="Quality below 10">
+##META=<ID=Assay,Type=String,Number=.,Values=[WholeGenome, Exome]>
+##SAMPLE=<ID=NA001,Assay=WholeGenome,Disease=None>
+##PEDIGREE=<Derived=NA001,Original=NA002>
+##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA001	NA002
+20	14370	rs6054257	G	A	29	PASS	AF=0.5;DP=14;DB	GT	0|0	1|0
+20	17330	.	T	A	3	q10	.	GT	0|0	0/1
+`
+	table, _ := ParseFile(strings.NewReader(vcfFile))
+	fmt.Println(table.Info["AF"].Description)
+	WriteFile(table, os.Stdout)
+	// Output:
+	// Allele "Frequency"
+	// ##fileformat=VCFv4.3
+	// ##contig=<ID=20,length=64444167,assembly="GRCh38, primary">
+	// ##INFO=<ID=DP,Number=1,Type=Integer,Description="Total Depth">
+	// ##INFO=<ID=DB,Number=0,Type=Flag,Description="dbSNP membership, build 129">
+	// ##INFO=<ID=AF,Number=A,Type=Float,Description="Allele \"Frequency\"">
+	// ##FILTER=<ID=q10,Description="Quality below 10">
+	// ##META=<ID=Assay,Type=String,Number=.,Values=[WholeGenome, Exome]>
+	// ##SAMPLE=<ID=NA001,Assay=WholeGenome,Disease=None>
+	// ##PEDIGREE=<Derived=NA001,Original=NA002>
+	// ##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">
+	// #CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	NA001	NA002
+	// 20	14370	rs6054257	G	A	29	PASS	DP=14;DB;AF=0.5	GT	0|0	1|0
+	// 20	17330	.	T	A	3	q10	.	GT	0|0	0/1
+}
+
+func ExampleWriter() {
+	h := NewHeader()
+	for _, line := range []string{
+		`##INFO=<ID=DB,Number=0,Type=Flag,Description="dbSNP membership">`,
+		`##INFO=<ID=SVTYPE,Number=1,Type=String,Description="Type of structural variant">`,
+	} {
+		meta, _ := ParseMetadata(line)
+		h.Add(meta)
+	}
+	rec := NewRecord()
+	rec.Chrom, rec.Pos, rec.Ref, rec.Alt = "1", 100, "A", "<DEL>"
+	rec.Info["DB"] = "1"
+	rec.Info["SVTYPE"] = "SVTYPE"
+	rec.Info["SOMATIC"] = ""
+	w := NewWriter(os.Stdout)
+	w.WriteHeader(h)
+	w.Write(rec)
+	w.Flush()
+	// Output:
+	// ##fileformat=VCFv4.2
+	// ##INFO=<ID=DB,Number=0,Type=Flag,Description="dbSNP membership">
+	// ##INFO=<ID=SVTYPE,Number=1,Type=String,Description="Type of structural variant">
+	// #CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO
+	// 1	100	.	A	<DEL>	.	.	DB;SVTYPE=SVTYPE;SOMATIC
+}
+
+func ExampleRecord_Sample() {
+	table, err := ParseFile(strings.NewReader(vcfstring))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(table.Samples)
+	for _, rec := range table.Records {
+		gt, _ := rec.SampleValue("SAMP002", "GT")
+		fields, _ := rec.Sample("SAMP002")
+		fmt.Println(rec.ID, gt, fields)
+	}
+
+	_, err = ParseFile(strings.NewReader("##fileformat=VCFv4.2\n#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO\tFORMAT\tS1\tS1\n"))
+	fmt.Println(err)
+	// Output:
+	// [SAMP001 SAMP002]
+	// rs11449 0/1 [0/1]
+	// rs84825 0/1 [0/1 0.03,0.97,0]
+	// rs84823 1/1 [1/1 10,5,0]
+	// vcf: line 2: duplicate sample name "S1"
+}
+
+func ExampleGetInfo() {
+	var vcfFile = `##fileformat=VCFv4.2
+##INFO=<ID=DP,Type=Integer,Number=1,Description="Total Depth">
+##INFO=<ID=AF,Number=A,Type=Float,Description="Allele Frequency">
+##INFO=<ID=DB,Number=0,Type=Flag,Description="dbSNP membership">
+##FORMAT=<ID=GT,Number=1,Type=String,Description="Genotype">
+##FORMAT=<ID=PL,Number=G,Type=Integer,Description="Phred-scaled Genotype Likelihoods">
+#CHROM	POS	ID	REF	ALT	QUAL	FILTER	INFO	FORMAT	S1	S2
+1	100	.	A	C,G	50	PASS	DP=.;AF=0.25,0.5	GT:PL	0/1:10,0,20,30,40,50	1:.
+1	200	.	T	C	50	PASS	DP=12;AF=0.1,0.2;DB	GT:PL	0/0:0,10,100	0|1:5,0
+1	300	.	G	A	50	PASS	DP=-2147483648;AF=0.5	GT:PL	0/1:20,0,30	0/0:0,20,200
+`
+	reader, _ := NewReader(strings.NewReader(vcfFile))
+	for reader.Next() {
+		rec := reader.Record()
+		dp, err := GetInfo[Integer](rec, "DP")
+		if err != nil {
+			fmt.Println(err)
+		}
+		db, _ := GetInfo[Flag](rec, "DB")
+		af, err := GetInfo[FloatVector](rec, "AF")
+		fmt.Println(dp.IsMissing(), dp, db, af, err)
+		for _, sample := range []string{"S1", "S2"} {
+			pl, err := GetSample[IntegerVector](rec, sample, "PL")
+			fmt.Println(sample, pl, err)
+		}
+	}
+	// Output:
+	// true -2147483648 false [0.25 0.5] <nil>
+	// S1 [10 0 20 30 40 50] <nil>
+	// S2 [-2147483648] <nil>
+	// false 12 true [] vcf: INFO AF: wrong number of values: 2, expected 1 (Number=A)
+	// S1 [0 10 100] <nil>
+	// S2 [] vcf: FORMAT PL: wrong number of values: 2, expected 3 (Number=G)
+	// vcf: INFO DP: integer -2147483648 is reserved for missing values
+	// false 0 false [0.5] <nil>
+	// S1 [20 0 30] <nil>
+	// S2 [0 20 200] <nil>
+}
+
+func ExampleParseMetadata() {
+	for _, line := range []string{
+		`##INFO=<ID=DP,Type=Integer,Number=1,Description="Total Depth">`,
+		`##FORMAT=<ID=AD,Type=Integer,Number=R,Description="Allelic depths">`,
+		`##META=<ID=Batch,Type=Integer>`,
+	} {
+		m, _ := ParseMetadata(line)
+		fmt.Println(m.ID, m.Type == IntegerType, m.Type == IntegerVectorType)
+		fmt.Println(m.Line())
+	}
+	// Output:
+	// DP true false
+	// ##INFO=<ID=DP,Type=Integer,Number=1,Description="Total Depth">
+	// AD false true
+	// ##FORMAT=<ID=AD,Type=Integer,Number=R,Description="Allelic depths">
+	// Batch false true
+	// ##META=<ID=Batch,Type=Integer>
+}
+
+func ExampleParseGenotype() {
+	for _, gt := range []string{"0/0", "0|1", "1/1", "1/2", "1", "./.", "0/1/1", "|0/1"} {
+		g, _ := ParseGenotype(gt)
+		fmt.Println(g, g.Alleles, g.Phased, g.Ploidy(), g.IsHomRef(), g.IsHet(), g.IsHomAlt(), g.AltDosage())
+	}
+
+	table, _ := ParseFile(strings.NewReader(vcfstring))
+	for _, rec := range table.Records {
+		gts, _ := rec.SampleGenotypes()
+		fmt.Println(rec.ID, gts)
+	}
+	// Output:
+	// 0/0 [0 0] [false false] 2 true false false 0
+	// 0|1 [0 1] [true true] 2 false true false 1
+	// 1/1 [1 1] [false false] 2 false false true 2
+	// 1/2 [1 2] [false false] 2 false true false 2
+	// 1 [1] [true] 1 false false true 1
+	// ./. [-1 -1] [false false] 2 false false false 0
+	// 0/1/1 [0 1 1] [false false false] 3 false true false 2
+	// |0/1 [0 1] [true false] 2 false true false 1
+	// rs11449 [0/0 0/1]
+	// rs84825 [0/1 0/1]
+	// rs84823 [./. 1/1]
 }
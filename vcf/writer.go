@@ -0,0 +1,147 @@
+package vcf
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultFileformat is the fileformat written for a header that
+// does not give one
+const DefaultFileformat = "VCFv4.2"
+
+// Writer writes VCF files to an io.Writer. The header should be
+// written first with WriteHeader; its INFO definitions then fix
+// the order of INFO fields in each record. Output is buffered;
+// Flush must be called once all records have been written.
+type Writer struct {
+	w      *bufio.Writer
+	header *Header
+}
+
+// NewWriter returns a new Writer that writes to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// WriteHeader writes the ##fileformat line, the other
+// meta-information lines of h in order, and the #CHROM line with
+// the sample names of h
+func (w *Writer) WriteHeader(h *Header) error {
+	w.header = h
+	fileformat := h.Fileformat
+	if len(fileformat) == 0 {
+		fileformat = DefaultFileformat
+	}
+	w.w.WriteString("##fileformat=" + fileformat + "\n")
+	for _, meta := range h.Lines() {
+		w.WriteMetadata(meta)
+	}
+	w.w.WriteString("#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO")
+	if len(h.Samples) > 0 {
+		w.w.WriteString("\tFORMAT\t" + strings.Join(h.Samples, "\t"))
+	}
+	return w.w.WriteByte('\n')
+}
+
+// WriteMetadata writes a single meta-information line
+func (w *Writer) WriteMetadata(m *Metadata) error {
+	w.w.WriteString(m.Line())
+	return w.w.WriteByte('\n')
+}
+
+// column returns s, or "." if s is empty
+func column(s string) string {
+	if len(s) == 0 {
+		return "."
+	}
+	return s
+}
+
+// infoString formats the INFO column of r, with fields in the
+// order of the header definitions followed by any others sorted by
+// key. Flags are written without a value; a field is a flag if
+// the header defines it as one or, for undefined fields, if its
+// value is empty or equal to its key.
+func (w *Writer) infoString(r *Record) string {
+	var keys, extra []string
+	if w.header != nil {
+		for _, id := range w.header.infoOrder() {
+			if _, ok := r.Info[id]; ok {
+				keys = append(keys, id)
+			}
+		}
+	}
+	for key := range r.Info {
+		if !slices.Contains(keys, key) {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	fields := make([]string, 0, len(r.Info))
+	for _, key := range append(keys, extra...) {
+		value := r.Info[key]
+		// Without a definition, a flag is recognized by its value
+		isFlag := value == key || len(value) == 0
+		if w.header != nil {
+			if meta, ok := w.header.Info[key]; ok {
+				isFlag = meta.Type == FlagType
+			}
+		}
+		if isFlag {
+			fields = append(fields, key)
+		} else {
+			fields = append(fields, key+"="+value)
+		}
+	}
+	return column(strings.Join(fields, ";"))
+}
+
+// Write writes a single record
+func (w *Writer) Write(r *Record) error {
+	qual := "."
+	if r.HasQual {
+		qual = strconv.FormatFloat(r.Qual, 'g', -1, 64)
+	}
+	cols := []string{r.Chrom, strconv.Itoa(r.Pos), column(r.ID), r.Ref,
+		column(r.Alt), qual, column(r.Filter), w.infoString(r)}
+	if len(r.Format) > 0 {
+		cols = append(cols, strings.Join(r.Format, ":"))
+		for _, gt := range r.Genotypes {
+			cols = append(cols, column(strings.Join(gt, ":")))
+		}
+	}
+	w.w.WriteString(strings.Join(cols, "\t"))
+	return w.w.WriteByte('\n')
+}
+
+// WriteAll writes a slice of records
+func (w *Writer) WriteAll(recs []*Record) error {
+	for _, rec := range recs {
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// WriteFile writes a complete vcf.Table, header and records, to
+// the given io.Writer
+func WriteFile(t *Table, w io.Writer) error {
+	vw := NewWriter(w)
+	if err := vw.WriteHeader(t.Header); err != nil {
+		return err
+	}
+	if err := vw.WriteAll(t.Records); err != nil {
+		return err
+	}
+	return vw.Flush()
+}